@@ -0,0 +1,147 @@
+package exceptions
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+var awsErrorCodes = map[string]Code{
+	"Throttling":                  AWS_THROTTLED,
+	"ThrottlingException":         AWS_THROTTLED,
+	"ThrottledException":          AWS_THROTTLED,
+	"TooManyRequestsException":    AWS_THROTTLED,
+	"RequestLimitExceeded":        AWS_THROTTLED,
+	"RequestThrottled":            AWS_THROTTLED,
+	"RequestThrottledException":   AWS_THROTTLED,
+	"SlowDown":                    AWS_THROTTLED,
+	"AccessDenied":                AWS_ACCESS_DENIED,
+	"AccessDeniedException":       AWS_ACCESS_DENIED,
+	"UnauthorizedOperation":       AWS_ACCESS_DENIED,
+	"InvalidClientTokenId":        AWS_ACCESS_DENIED,
+	"SignatureDoesNotMatch":       AWS_ACCESS_DENIED,
+	"ExpiredToken":                AWS_ACCESS_DENIED,
+	"ExpiredTokenException":       AWS_ACCESS_DENIED,
+	"UnrecognizedClientException": AWS_ACCESS_DENIED,
+	"AuthFailure":                 AWS_ACCESS_DENIED,
+	"NoSuchHostedZone":            AWS_NOT_FOUND,
+	"NoSuchDistribution":          AWS_NOT_FOUND,
+	"InvalidInstanceID.NotFound":  AWS_NOT_FOUND,
+	"ResourceNotFoundException":   AWS_NOT_FOUND,
+	"ValidationError":             AWS_VALIDATION,
+	"ValidationException":         AWS_VALIDATION,
+	"InvalidInput":                AWS_VALIDATION,
+	"InvalidChangeBatch":          AWS_VALIDATION,
+	"InvalidArgument":             AWS_VALIDATION,
+	"InvalidParameterValue":       AWS_VALIDATION,
+	"PreconditionFailed":          PRECONDITION_FAILED,
+	"InvalidIfMatchVersion":       PRECONDITION_FAILED,
+	"PriorRequestNotComplete":     AWS_CONFLICT,
+	"ScalingActivityInProgress":   AWS_CONFLICT,
+	"ResourceContention":          AWS_CONFLICT,
+	"IncorrectInstanceState":      AWS_CONFLICT,
+	"ServiceUnavailable":          AWS_UNAVAILABLE,
+	"ServiceUnavailableException": AWS_UNAVAILABLE,
+	"InternalError":               AWS_UNAVAILABLE,
+	"InternalFailure":             AWS_UNAVAILABLE,
+	"ServiceFailure":              AWS_UNAVAILABLE,
+}
+
+// Classify maps any error to a defined code, looking through wrapped errors
+func Classify(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	var wrappedError *WrappedError
+	if errors.As(err, &wrappedError) && wrappedError.Code != "" {
+		return wrappedError.Code
+	}
+
+	var apiError *ApiError
+	if errors.As(err, &apiError) && apiError.Status > 0 {
+		return ClassifyStatus(apiError.Status)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return CANCELED
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return TIMEOUT
+	}
+
+	var awsApiError smithy.APIError
+	if errors.As(err, &awsApiError) {
+		code, ok := awsErrorCodes[awsApiError.ErrorCode()]
+		if ok {
+			return code
+		}
+	}
+
+	var responseError *awshttp.ResponseError
+	if errors.As(err, &responseError) {
+		return classifyAwsStatus(responseError.HTTPStatusCode())
+	}
+
+	if awsApiError != nil {
+		return AWS_ERROR
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) {
+		if netError.Timeout() {
+			return TIMEOUT
+		}
+
+		return NETWORK
+	}
+
+	return UNKNOWN
+}
+
+func ClassifyStatus(status int) Code {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return HTTP_UNAUTHORIZED
+	case status == http.StatusNotFound:
+		return HTTP_NOT_FOUND
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return TIMEOUT
+	case status == http.StatusPreconditionFailed:
+		return PRECONDITION_FAILED
+	case status == http.StatusTooManyRequests:
+		return HTTP_THROTTLED
+	case status >= http.StatusInternalServerError:
+		return HTTP_SERVER_ERROR
+	case status >= http.StatusBadRequest:
+		return HTTP_CLIENT_ERROR
+	}
+
+	return UNKNOWN
+}
+
+func classifyAwsStatus(status int) Code {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return AWS_ACCESS_DENIED
+	case status == http.StatusNotFound:
+		return AWS_NOT_FOUND
+	case status == http.StatusConflict:
+		return AWS_CONFLICT
+	case status == http.StatusPreconditionFailed:
+		return PRECONDITION_FAILED
+	case status == http.StatusTooManyRequests:
+		return AWS_THROTTLED
+	case status >= http.StatusInternalServerError:
+		return AWS_UNAVAILABLE
+	case status >= http.StatusBadRequest:
+		return AWS_VALIDATION
+	}
+
+	return AWS_ERROR
+}
@@ -0,0 +1,96 @@
+package exceptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func awsResponseError(status int, err error) error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      err,
+		},
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected Code
+	}{
+		{"nil", nil, ""},
+		{"wrapped code kept", fmt.Errorf("step: %w", NewWrappedError(CONFIG, errors.New("missing"))), CONFIG},
+		{"api status", &ApiError{Status: http.StatusTooManyRequests}, HTTP_THROTTLED},
+		{"api cause without status", &ApiError{Err: context.Canceled}, CANCELED},
+		{"canceled", fmt.Errorf("request: %w", context.Canceled), CANCELED},
+		{"deadline", context.DeadlineExceeded, TIMEOUT},
+		{"aws throttling", &smithy.GenericAPIError{Code: "Throttling"}, AWS_THROTTLED},
+		{"aws access denied", &smithy.GenericAPIError{Code: "AccessDenied"}, AWS_ACCESS_DENIED},
+		{"aws unknown code with status", awsResponseError(http.StatusConflict, &smithy.GenericAPIError{Code: "Unexpected"}), AWS_CONFLICT},
+		{"aws unknown code", &smithy.GenericAPIError{Code: "Unexpected"}, AWS_ERROR},
+		{"aws status without code", awsResponseError(http.StatusServiceUnavailable, errors.New("unavailable")), AWS_UNAVAILABLE},
+		{"network timeout", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, TIMEOUT},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("refused")}, NETWORK},
+		{"other", errors.New("boom"), UNKNOWN},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := Classify(test.err); code != test.expected {
+				t.Errorf("Classify = %s, expected %s", code, test.expected)
+			}
+		})
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected Code
+	}{
+		{http.StatusForbidden, HTTP_UNAUTHORIZED},
+		{http.StatusNotFound, HTTP_NOT_FOUND},
+		{http.StatusGatewayTimeout, TIMEOUT},
+		{http.StatusPreconditionFailed, PRECONDITION_FAILED},
+		{http.StatusTooManyRequests, HTTP_THROTTLED},
+		{http.StatusBadGateway, HTTP_SERVER_ERROR},
+		{http.StatusConflict, HTTP_CLIENT_ERROR},
+		{http.StatusMultipleChoices, UNKNOWN},
+	}
+
+	for _, test := range tests {
+		if code := ClassifyStatus(test.status); code != test.expected {
+			t.Errorf("ClassifyStatus(%d) = %s, expected %s", test.status, code, test.expected)
+		}
+	}
+}
+
+func TestDecision(t *testing.T) {
+	tests := []struct {
+		code     Code
+		expected Decision
+	}{
+		{AWS_ACCESS_DENIED, Decision{Alert: true, Abort: true}},
+		{AWS_THROTTLED, Decision{Retry: true}},
+		{AWS_VALIDATION, Decision{Alert: true}},
+		{CANCELED, Decision{Abort: true}},
+		{"NOT_A_CODE", Decision{Retry: true, Alert: true}},
+	}
+
+	for _, test := range tests {
+		if decision := test.code.Decision(); decision != test.expected {
+			t.Errorf("%s decision = %+v, expected %+v", test.code, decision, test.expected)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package exceptions
+
+type Code string
+
+const (
+	UNKNOWN             Code = "UNKNOWN"
+	CONFIG              Code = "CONFIG"
+	CANCELED            Code = "CANCELED"
+	TIMEOUT             Code = "TIMEOUT"
+	NETWORK             Code = "NETWORK"
+	PROBE_TIMEOUT       Code = "PROBE_TIMEOUT"
+	PROBE_FAILED        Code = "PROBE_FAILED"
+	INVALID_RESPONSE    Code = "INVALID_RESPONSE"
+	PRECONDITION_FAILED Code = "PRECONDITION_FAILED"
+	HTTP_UNAUTHORIZED   Code = "HTTP_UNAUTHORIZED"
+	HTTP_NOT_FOUND      Code = "HTTP_NOT_FOUND"
+	HTTP_THROTTLED      Code = "HTTP_THROTTLED"
+	HTTP_CLIENT_ERROR   Code = "HTTP_CLIENT_ERROR"
+	HTTP_SERVER_ERROR   Code = "HTTP_SERVER_ERROR"
+	AWS_THROTTLED       Code = "AWS_THROTTLED"
+	AWS_ACCESS_DENIED   Code = "AWS_ACCESS_DENIED"
+	AWS_NOT_FOUND       Code = "AWS_NOT_FOUND"
+	AWS_VALIDATION      Code = "AWS_VALIDATION"
+	AWS_CONFLICT        Code = "AWS_CONFLICT"
+	AWS_UNAVAILABLE     Code = "AWS_UNAVAILABLE"
+	AWS_ERROR           Code = "AWS_ERROR"
+)
+
+// Decision tells callers whether an operation failing with a code is worth retrying later,
+// should raise an alert, or must abort the remaining steps because they would fail the same way
+type Decision struct {
+	Retry bool
+	Alert bool
+	Abort bool
+}
+
+var decisions = map[Code]Decision{
+	UNKNOWN:             {Retry: true, Alert: true},
+	CONFIG:              {Alert: true, Abort: true},
+	CANCELED:            {Abort: true},
+	TIMEOUT:             {Retry: true},
+	NETWORK:             {Retry: true},
+	PROBE_TIMEOUT:       {Retry: true},
+	PROBE_FAILED:        {},
+	INVALID_RESPONSE:    {Retry: true},
+	PRECONDITION_FAILED: {Retry: true},
+	HTTP_UNAUTHORIZED:   {Alert: true},
+	HTTP_NOT_FOUND:      {Alert: true},
+	HTTP_THROTTLED:      {Retry: true},
+	HTTP_CLIENT_ERROR:   {Alert: true},
+	HTTP_SERVER_ERROR:   {Retry: true},
+	AWS_THROTTLED:       {Retry: true},
+	AWS_ACCESS_DENIED:   {Alert: true, Abort: true},
+	AWS_NOT_FOUND:       {Alert: true},
+	AWS_VALIDATION:      {Alert: true},
+	AWS_CONFLICT:        {Retry: true},
+	AWS_UNAVAILABLE:     {Retry: true, Alert: true},
+	AWS_ERROR:           {Retry: true, Alert: true},
+}
+
+func (code Code) Decision() Decision {
+	decision, ok := decisions[code]
+	if !ok {
+		return decisions[UNKNOWN]
+	}
+
+	return decision
+}
+
+func (code Code) IsRetryable() bool {
+	return code.Decision().Retry
+}
+
+func (code Code) ShouldAlert() bool {
+	return code.Decision().Alert
+}
+
+func (code Code) ShouldAbort() bool {
+	return code.Decision().Abort
+}
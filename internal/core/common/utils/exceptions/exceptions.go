@@ -3,8 +3,16 @@ package exceptions
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fmt"
 	"net/http"
+	"strings"
+)
+
+const (
+	API_ERROR_MESSAGE     = "Error calling API."
+	MAX_ERROR_BODY_LENGTH = 512
 )
 
 var (
@@ -15,9 +23,9 @@ var (
 )
 
 type WrappedError struct {
-	Error     error
+	Err       error
 	Message   string
-	Code      string
+	Code      Code
 	BaseError BaseError
 }
 
@@ -28,6 +36,7 @@ type BaseError struct {
 }
 
 type ApiError struct {
+	Err          error
 	Message      string
 	ResponseBody string
 	Status       int
@@ -36,25 +45,113 @@ type ApiError struct {
 func (apiError ApiError) ToWrappedError(ctx *context.Context) *WrappedError {
 	baseError := BaseError{
 		Code:       constants.API_ERROR,
-		Message:    "Error calling API.",
+		Message:    API_ERROR_MESSAGE,
 		HttpStatus: http.StatusInternalServerError,
 	}
 
-	err := json.Unmarshal([]byte(apiError.ResponseBody), &baseError)
-	if err == nil {
+	if apiError.Status > 0 {
 		baseError.HttpStatus = apiError.Status
 	}
 
-	wrappedError := WrappedError{
+	message := apiError.Message
+	if apiError.ResponseBody != "" {
+		err := json.Unmarshal([]byte(apiError.ResponseBody), &baseError)
+		if err != nil {
+			baseError.Message = API_ERROR_MESSAGE
+		}
+	}
+
+	// bodies that are not an error document, like proxy HTML pages, are kept in the message
+	if message == "" && apiError.Status > 0 && baseError.Message == API_ERROR_MESSAGE {
+		message = apiError.describe()
+	}
+
+	return &WrappedError{
+		Err:       apiError.Err,
+		Message:   message,
+		Code:      Classify(&apiError),
 		BaseError: baseError,
 	}
+}
+
+// describe reports the status with the start of the response body
+func (apiError *ApiError) describe() string {
+	description := fmt.Sprintf("API returned %d %s", apiError.Status, http.StatusText(apiError.Status))
+
+	body := strings.TrimSpace(apiError.ResponseBody)
+	if body == "" {
+		return description
+	}
+
+	if len(body) > MAX_ERROR_BODY_LENGTH {
+		body = strings.ToValidUTF8(body[:MAX_ERROR_BODY_LENGTH], "") + "..."
+	}
+
+	return description + ": " + body
+}
+
+func (apiError *ApiError) Error() string {
+	if apiError.Message != "" {
+		return apiError.Message
+	}
+
+	if apiError.Err != nil {
+		return apiError.Err.Error()
+	}
+
+	return apiError.describe()
+}
+
+func (apiError *ApiError) Unwrap() error {
+	return apiError.Err
+}
+
+func NewWrappedError(code Code, err error) *WrappedError {
+	return &WrappedError{
+		Err:  err,
+		Code: code,
+	}
+}
+
+// Wrap keeps an existing code from a wrapped error and classifies anything else
+func Wrap(err error) *WrappedError {
+	var wrappedError *WrappedError
+	if errors.As(err, &wrappedError) {
+		return wrappedError
+	}
+
+	return NewWrappedError(Classify(err), err)
+}
+
+func (wrappedError *WrappedError) Error() string {
+	return wrappedError.GetMessage()
+}
+
+func (wrappedError *WrappedError) Unwrap() error {
+	return wrappedError.Err
+}
+
+// Is matches another wrapped error by code, so codes can be used as sentinels with errors.Is
+func (wrappedError *WrappedError) Is(target error) bool {
+	targetError, ok := target.(*WrappedError)
+	return ok && targetError.Code != "" && targetError.Code == wrappedError.GetCode()
+}
 
-	return &wrappedError
+func (wrappedError *WrappedError) IsRetryable() bool {
+	return wrappedError.GetCode().IsRetryable()
+}
+
+func (wrappedError *WrappedError) ShouldAlert() bool {
+	return wrappedError.GetCode().ShouldAlert()
+}
+
+func (wrappedError *WrappedError) ShouldAbort() bool {
+	return wrappedError.GetCode().ShouldAbort()
 }
 
 func (wrappedError WrappedError) GetMessage() string {
-	if wrappedError.Error != nil {
-		return wrappedError.Error.Error()
+	if wrappedError.Err != nil {
+		return wrappedError.Err.Error()
 	}
 
 	if wrappedError.Message != "" {
@@ -68,14 +165,14 @@ func (wrappedError WrappedError) GetMessage() string {
 	return ""
 }
 
-func (wrappedError WrappedError) GetCode() string {
+func (wrappedError WrappedError) GetCode() Code {
 	if wrappedError.Code != "" {
 		return wrappedError.Code
 	}
 
-	if wrappedError.BaseError.Code != "" {
-		return wrappedError.BaseError.Code
+	if wrappedError.Err != nil {
+		return Classify(wrappedError.Err)
 	}
 
-	return ""
+	return UNKNOWN
 }
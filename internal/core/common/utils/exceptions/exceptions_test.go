@@ -0,0 +1,53 @@
+package exceptions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestApiErrorToWrappedError(t *testing.T) {
+	longBody := "<html>" + strings.Repeat("x", 2*MAX_ERROR_BODY_LENGTH) + "</html>"
+
+	tests := []struct {
+		name     string
+		apiError ApiError
+		expected string
+		code     Code
+	}{
+		{"json error document", ApiError{Status: http.StatusBadRequest, ResponseBody: `{"message":"Invalid record"}`}, "Invalid record", HTTP_CLIENT_ERROR},
+		{"html body", ApiError{Status: http.StatusBadGateway, ResponseBody: "<html>Bad Gateway</html>\n"}, "API returned 502 Bad Gateway: <html>Bad Gateway</html>", HTTP_SERVER_ERROR},
+		{"plain text body", ApiError{Status: http.StatusUnauthorized, ResponseBody: "invalid token"}, "API returned 401 Unauthorized: invalid token", HTTP_UNAUTHORIZED},
+		{"json without message", ApiError{Status: http.StatusNotFound, ResponseBody: `{"error":"missing"}`}, `API returned 404 Not Found: {"error":"missing"}`, HTTP_NOT_FOUND},
+		{"empty body", ApiError{Status: http.StatusServiceUnavailable}, "API returned 503 Service Unavailable", HTTP_SERVER_ERROR},
+		{"truncated body", ApiError{Status: http.StatusInternalServerError, ResponseBody: longBody}, "API returned 500 Internal Server Error: " + longBody[:MAX_ERROR_BODY_LENGTH] + "...", HTTP_SERVER_ERROR},
+		{"explicit message", ApiError{Status: http.StatusOK, ResponseBody: "<xml", Message: "Error on unmarshalling response body"}, "Error on unmarshalling response body", UNKNOWN},
+		{"cause", ApiError{Err: errors.New("connection refused")}, "connection refused", UNKNOWN},
+	}
+
+	ctx := context.Background()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wrappedError := test.apiError.ToWrappedError(&ctx)
+
+			if message := wrappedError.GetMessage(); message != test.expected {
+				t.Errorf("message %q, expected %q", message, test.expected)
+			}
+
+			if wrappedError.GetCode() != test.code {
+				t.Errorf("code %s, expected %s", wrappedError.GetCode(), test.code)
+			}
+		})
+	}
+}
+
+func TestApiErrorMessage(t *testing.T) {
+	apiError := &ApiError{Status: http.StatusBadGateway, ResponseBody: "upstream down"}
+
+	if message := apiError.Error(); message != "API returned 502 Bad Gateway: upstream down" {
+		t.Errorf("Error() = %s", message)
+	}
+}
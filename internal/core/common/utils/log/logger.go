@@ -2,27 +2,32 @@ package log
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
 	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/format"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/sink"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
-	zlog "github.com/rs/zerolog/log"
 )
 
 var currentLevel = TRACE
-var currentFormat = format.TEXT
+var stdout io.Writer = os.Stdout
+var sinks []*logSink
+var sinksMutex sync.RWMutex
 
 type LoggerEvent struct {
 	traceMap map[string]any
-	event    *zerolog.Event
 	caller   string
 	level    Level
 }
@@ -44,16 +49,28 @@ const (
 
 	DEFAULT_CALLER_LEVEL = 2
 	PANIC_CALLER_LEVEL   = 3
+
+	TRACE_ID_BYTES = 8
+)
+
+const (
+	TRACE_LOOP        = "loop"
+	TRACE_CYCLE_ID    = "cycleId"
+	TRACE_FAILOVER_ID = "failoverId"
+	TRACE_REQUEST_ID  = "requestId"
+	TRACE_TRIGGER     = "trigger"
+	TRACE_GROUP       = "group"
+	TRACE_LINK        = "link"
+	TRACE_PRINCIPAL   = "principal"
 )
 
 func SetupLogger(profile string) {
-	loggingLevel := os.Getenv(constants.LOGGING_LEVEL)
-	setCurrentLevel(loggingLevel)
+	loggingLevel := parseLevel(os.Getenv(constants.LOGGING_LEVEL))
 
 	if DEV_PROFILE == profile {
-		setLoggerText(true)
+		setSinks([]*logSink{newWriterSink(stdout, format.TEXT, loggingLevel, true)})
 	} else {
-		setLoggerJson()
+		setSinks([]*logSink{newWriterSink(stdout, format.JSON, loggingLevel, false)})
 	}
 }
 
@@ -90,54 +107,105 @@ func getStackTrace() string {
 	}
 }
 
-func ReconfigureLogger(ctx *context.Context, configFormat format.Format, level string, colored bool) {
+// UseStderr sends the stdout sink to stderr, keeping stdout clean for command output
+func UseStderr() {
+	stdout = os.Stderr
+}
+
+func ReconfigureLogger(ctx *context.Context, configFormat format.Format, level string, colored bool, sinkConfigs []sink.Sink) error {
 	Info(ctx).Msg("Reconfiguring logger for level: " + strings.ToUpper(level))
-	setCurrentLevel(level)
 
-	if configFormat == format.TEXT {
-		setLoggerText(colored)
-	} else {
-		setLoggerJson()
+	if len(sinkConfigs) == 0 {
+		sinkConfigs = []sink.Sink{
+			{
+				Type:    sink.STDOUT,
+				Level:   level,
+				Format:  configFormat,
+				Colored: colored,
+			},
+		}
+	}
+
+	var newSinks []*logSink
+	for _, sinkConfig := range sinkConfigs {
+		if len(sinkConfig.Level) == 0 {
+			sinkConfig.Level = level
+		}
+
+		if len(sinkConfig.Format) == 0 {
+			sinkConfig.Format = configFormat
+		}
+
+		logSink, err := newSink(sinkConfig)
+		if err != nil {
+			closeSinks(newSinks)
+			return err
+		}
+
+		newSinks = append(newSinks, logSink)
 	}
+
+	closeSinks(setSinks(newSinks))
+	return nil
 }
 
-func setCurrentLevel(level string) {
+func parseLevel(level string) Level {
 	switch strings.ToUpper(level) {
 	case "FATAL":
-		zerolog.SetGlobalLevel(zerolog.FatalLevel)
-		currentLevel = FATAL
+		return FATAL
 	case "ERROR":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-		currentLevel = ERROR
+		return ERROR
 	case "WARN":
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
-		currentLevel = WARN
+		return WARN
 	case "INFO":
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
-		currentLevel = INFO
+		return INFO
 	case "DEBUG":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-		currentLevel = DEBUG
+		return DEBUG
 	default:
-		zerolog.SetGlobalLevel(zerolog.TraceLevel)
-		currentLevel = TRACE
+		return TRACE
 	}
 }
 
-func setLoggerJson() {
-	currentFormat = format.JSON
-	zerolog.TimeFieldFormat = TIMESTAMP_LOG_FORMAT
-	zlog.Logger = zerolog.New(os.Stdout)
+func (level Level) zerologLevel() zerolog.Level {
+	switch level {
+	case FATAL:
+		return zerolog.FatalLevel
+	case ERROR:
+		return zerolog.ErrorLevel
+	case WARN:
+		return zerolog.WarnLevel
+	case INFO:
+		return zerolog.InfoLevel
+	case DEBUG:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.TraceLevel
+	}
 }
 
-func setLoggerText(colored bool) {
-	currentFormat = format.TEXT
-	output := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		TimeFormat: TIMESTAMP_LOG_FORMAT,
-		NoColor:    !colored,
+func setSinks(newSinks []*logSink) []*logSink {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+
+	minLevel := FATAL
+	for _, logSink := range newSinks {
+		minLevel = min(minLevel, logSink.level)
 	}
-	zlog.Logger = zerolog.New(output).With().Timestamp().Logger()
+
+	oldSinks := sinks
+	sinks = newSinks
+	currentLevel = minLevel
+	zerolog.TimeFieldFormat = TIMESTAMP_LOG_FORMAT
+	zerolog.SetGlobalLevel(minLevel.zerologLevel())
+
+	return oldSinks
+}
+
+func getSinks() []*logSink {
+	sinksMutex.RLock()
+	defer sinksMutex.RUnlock()
+
+	return sinks
 }
 
 func IsLevelEnabled(level Level) bool {
@@ -172,59 +240,45 @@ func (loggerEvent LoggerEvent) Wrap(err *exceptions.WrappedError) {
 func (loggerEvent LoggerEvent) Msg(msg string) {
 	if IsLevelEnabled(loggerEvent.level) {
 		now := time.Now()
-		traceMap := loggerEvent.traceMap
-		event := loggerEvent.event
-		caller := loggerEvent.caller
-
-		if format.JSON == currentFormat {
-			event.Time("@timestamp", now)
-		}
 
-		if traceMap != nil {
-			if format.TEXT == currentFormat {
-				for key, value := range traceMap {
-					event = event.Any(key, value)
-				}
-			} else {
-				event = event.Interface("trace", traceMap)
+		for _, logSink := range getSinks() {
+			if loggerEvent.level >= logSink.level {
+				logSink.write(now, loggerEvent, msg)
 			}
 		}
 
-		if caller != constants.EMPTY {
-			event = event.Str("caller", caller)
+		if loggerEvent.level == FATAL {
+			os.Exit(1)
 		}
-
-		event.Msg(msg)
 	}
 }
 
 func Trace(ctx *context.Context) LoggerEvent {
-	return CreateLoggerEvent(ctx, zlog.Trace(), TRACE)
+	return CreateLoggerEvent(ctx, TRACE)
 }
 
 func Debug(ctx *context.Context) LoggerEvent {
-	return CreateLoggerEvent(ctx, zlog.Debug(), DEBUG)
+	return CreateLoggerEvent(ctx, DEBUG)
 }
 
 func Info(ctx *context.Context) LoggerEvent {
-	return CreateLoggerEvent(ctx, zlog.Info(), INFO)
+	return CreateLoggerEvent(ctx, INFO)
 }
 
 func Warn(ctx *context.Context) LoggerEvent {
-	return CreateLoggerEvent(ctx, zlog.Warn(), WARN)
+	return CreateLoggerEvent(ctx, WARN)
 }
 
 func Error(ctx *context.Context) LoggerEvent {
-	return CreateLoggerEvent(ctx, zlog.Error(), ERROR)
+	return CreateLoggerEvent(ctx, ERROR)
 }
 
 func Fatal(ctx *context.Context) LoggerEvent {
-	return CreateLoggerEvent(ctx, zlog.Fatal(), FATAL)
+	return CreateLoggerEvent(ctx, FATAL)
 }
 
-func CreateLoggerEvent(ctx *context.Context, event *zerolog.Event, level Level) LoggerEvent {
+func CreateLoggerEvent(ctx *context.Context, level Level) LoggerEvent {
 	loggerEvent := LoggerEvent{
-		event: event,
 		level: level,
 	}
 
@@ -258,3 +312,43 @@ func NewLogWritter(event LoggerEvent) *LogWritter {
 		event: event,
 	}
 }
+
+func WithTrace(ctx *context.Context, key string, value any) *context.Context {
+	traceMap := make(map[string]any)
+
+	traceObj := (*ctx).Value(constants.TRACE_MAP)
+	if traceObj != nil {
+		for key, value := range traceObj.(map[string]any) {
+			traceMap[key] = value
+		}
+	}
+
+	traceMap[key] = value
+	newCtx := context.WithValue(*ctx, constants.TRACE_MAP, traceMap)
+
+	return &newCtx
+}
+
+func WithCycle(ctx *context.Context, loop string) *context.Context {
+	loopCtx := WithTrace(ctx, TRACE_LOOP, loop)
+	return WithTrace(loopCtx, TRACE_CYCLE_ID, NewTraceId())
+}
+
+func NewTraceId() string {
+	bytes := make([]byte, TRACE_ID_BYTES)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(bytes)
+}
+
+func GetTrace(ctx *context.Context, key string) any {
+	traceObj := (*ctx).Value(constants.TRACE_MAP)
+	if traceObj == nil {
+		return nil
+	}
+
+	return traceObj.(map[string]any)[key]
+}
@@ -0,0 +1,199 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	REDACTED_VALUE          = "REDACTED"
+	DEFAULT_MAX_BODY_LENGTH = 4096
+
+	HTTP_HEADER_SEPARATOR = "\r\n\r\n"
+	HTTP_LINE_SEPARATOR   = "\r\n"
+
+	// Telegram carries the bot token in the path, as in /bot<token>/sendMessage
+	BOT_TOKEN_PREFIX = "bot"
+)
+
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Amz-Security-Token",
+}
+
+var defaultRedactedFields = []string{
+	"password",
+	"secret",
+	"token",
+	"accessKey",
+	"secretKey",
+	"apiKey",
+}
+
+var redactedHeaders = toLowerSet(defaultRedactedHeaders)
+var redactedFields = toLowerSet(defaultRedactedFields)
+var maxBodyLength = DEFAULT_MAX_BODY_LENGTH
+var redactionMutex sync.RWMutex
+
+func SetRedaction(headers []string, fields []string, bodyLength int) {
+	redactionMutex.Lock()
+	defer redactionMutex.Unlock()
+
+	redactedHeaders = toLowerSet(append(append([]string{}, defaultRedactedHeaders...), headers...))
+	redactedFields = toLowerSet(append(append([]string{}, defaultRedactedFields...), fields...))
+
+	maxBodyLength = DEFAULT_MAX_BODY_LENGTH
+	if bodyLength != 0 {
+		maxBodyLength = bodyLength
+	}
+}
+
+func RedactHeader(name string, value string) string {
+	redactionMutex.RLock()
+	defer redactionMutex.RUnlock()
+
+	if redactedHeaders[strings.ToLower(name)] {
+		return REDACTED_VALUE
+	}
+
+	return value
+}
+
+func RedactUrl(requestUrl *url.URL) string {
+	if requestUrl == nil {
+		return ""
+	}
+
+	redactionMutex.RLock()
+	defer redactionMutex.RUnlock()
+
+	redactedUrl := *requestUrl
+	if redactedUrl.User != nil {
+		redactedUrl.User = url.UserPassword(redactedUrl.User.Username(), REDACTED_VALUE)
+	}
+
+	query := redactedUrl.Query()
+	changed := false
+	for name := range query {
+		if redactedFields[strings.ToLower(name)] {
+			query.Set(name, REDACTED_VALUE)
+			changed = true
+		}
+	}
+
+	if changed {
+		redactedUrl.RawQuery = query.Encode()
+	}
+
+	redactedUrl.Path = redactPath(redactedUrl.Path)
+	redactedUrl.RawPath = ""
+
+	return redactedUrl.String()
+}
+
+// RedactRawUrl is RedactUrl for URLs kept as strings, unparseable ones are fully masked
+func RedactRawUrl(rawUrl string) string {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return REDACTED_VALUE
+	}
+
+	return RedactUrl(parsedUrl)
+}
+
+func redactPath(path string) string {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if len(segment) > len(BOT_TOKEN_PREFIX) && strings.HasPrefix(segment, BOT_TOKEN_PREFIX) && strings.Contains(segment, ":") {
+			segments[index] = BOT_TOKEN_PREFIX + REDACTED_VALUE
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// RedactBody masks configured fields when the body is JSON and truncates it to the max body length
+func RedactBody(body []byte) string {
+	redactionMutex.RLock()
+	defer redactionMutex.RUnlock()
+
+	redacted := string(body)
+
+	var value any
+	if json.Unmarshal(body, &value) == nil {
+		if redactValue(value) {
+			data, err := json.Marshal(value)
+			if err == nil {
+				redacted = string(data)
+			}
+		}
+	}
+
+	if maxBodyLength > 0 && len(redacted) > maxBodyLength {
+		return fmt.Sprintf("%s... (%d bytes truncated)", redacted[:maxBodyLength], len(redacted)-maxBodyLength)
+	}
+
+	return redacted
+}
+
+// RedactDump masks headers and body of a raw HTTP dump, as written by the AWS SDK client log mode
+func RedactDump(dump string) string {
+	head, body, hasBody := strings.Cut(dump, HTTP_HEADER_SEPARATOR)
+
+	lines := strings.Split(head, HTTP_LINE_SEPARATOR)
+	for index, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if index > 0 && found {
+			lines[index] = name + ": " + RedactHeader(name, strings.TrimSpace(value))
+		}
+	}
+
+	redacted := strings.Join(lines, HTTP_LINE_SEPARATOR)
+	if hasBody {
+		redacted += HTTP_HEADER_SEPARATOR + RedactBody([]byte(body))
+	}
+
+	return redacted
+}
+
+func redactValue(value any) bool {
+	changed := false
+
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			if redactedFields[strings.ToLower(key)] {
+				typed[key] = REDACTED_VALUE
+				changed = true
+			} else if redactValue(child) {
+				changed = true
+			}
+		}
+
+	case []any:
+		for _, child := range typed {
+			if redactValue(child) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+func toLowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+
+	return set
+}
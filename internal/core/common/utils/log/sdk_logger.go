@@ -0,0 +1,38 @@
+package log
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fmt"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+type SdkLogger struct {
+	ctx *context.Context
+}
+
+func NewSdkLogger(ctx *context.Context) *SdkLogger {
+	return &SdkLogger{
+		ctx: ctx,
+	}
+}
+
+func (logger *SdkLogger) Logf(classification logging.Classification, format string, values ...any) {
+	message := RedactDump(fmt.Sprintf(format, values...))
+
+	if classification == logging.Warn {
+		Warn(logger.ctx).Caller("aws-sdk").Msg(message)
+	} else {
+		Debug(logger.ctx).Caller("aws-sdk").Msg(message)
+	}
+}
+
+func (logger *SdkLogger) WithContext(ctx context.Context) logging.Logger {
+	// keep the trace map of the caller when the SDK hands over its own operation context
+	if ctx.Value(constants.TRACE_MAP) == nil {
+		return logger
+	}
+
+	return NewSdkLogger(&ctx)
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/rotate"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/format"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/sink"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type logSink struct {
+	logger zerolog.Logger
+	format format.Format
+	level  Level
+	closer io.Closer
+}
+
+func newSink(sinkConfig sink.Sink) (*logSink, error) {
+	level := parseLevel(sinkConfig.Level)
+
+	switch sinkConfig.Type {
+	case sink.STDOUT, constants.EMPTY:
+		return newWriterSink(stdout, sinkConfig.Format, level, sinkConfig.Colored), nil
+
+	case sink.FILE:
+		fileConfig := sinkConfig.File
+
+		writer, err := rotate.NewWriter(fileConfig.Path, fileConfig.MaxSize, fileConfig.Interval, fileConfig.MaxAge, fileConfig.MaxBackups)
+		if err != nil {
+			return nil, errors.New("Error on opening log file: " + err.Error())
+		}
+
+		logSink := newWriterSink(writer, sinkConfig.Format, level, sinkConfig.Colored)
+		logSink.closer = writer
+
+		return logSink, nil
+
+	case sink.SYSLOG:
+		return newSyslogSink(sinkConfig.Syslog, sinkConfig.Format, level)
+	}
+
+	return nil, errors.New("Unknown log sink type: " + string(sinkConfig.Type))
+}
+
+func newWriterSink(writer io.Writer, sinkFormat format.Format, level Level, colored bool) *logSink {
+	if sinkFormat == format.TEXT {
+		output := zerolog.ConsoleWriter{
+			Out:        writer,
+			TimeFormat: TIMESTAMP_LOG_FORMAT,
+			NoColor:    !colored,
+		}
+
+		return &logSink{
+			logger: zerolog.New(output).With().Timestamp().Logger(),
+			format: format.TEXT,
+			level:  level,
+		}
+	}
+
+	return &logSink{
+		logger: zerolog.New(writer),
+		format: format.JSON,
+		level:  level,
+	}
+}
+
+func (logSink *logSink) write(now time.Time, loggerEvent LoggerEvent, msg string) {
+	event := logSink.logger.WithLevel(loggerEvent.level.zerologLevel())
+	traceMap := loggerEvent.traceMap
+
+	if format.JSON == logSink.format {
+		event.Time("@timestamp", now)
+	}
+
+	if len(traceMap) > 0 {
+		if format.TEXT == logSink.format {
+			for key, value := range traceMap {
+				event = event.Any(key, value)
+			}
+		} else {
+			event = event.Interface("trace", traceMap)
+		}
+	}
+
+	if loggerEvent.caller != constants.EMPTY {
+		event = event.Str("caller", loggerEvent.caller)
+	}
+
+	event.Msg(msg)
+}
+
+func closeSinks(logSinks []*logSink) {
+	for _, logSink := range logSinks {
+		if logSink.closer != nil {
+			logSink.closer.Close()
+		}
+	}
+}
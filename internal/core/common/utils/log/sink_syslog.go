@@ -0,0 +1,123 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/format"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/sink"
+	"log/syslog"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+const DEFAULT_SYSLOG_TAG = "aws-infrastructure-helper"
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"daemon":   syslog.LOG_DAEMON,
+	"syslog":   syslog.LOG_SYSLOG,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+	"authpriv": syslog.LOG_AUTHPRIV,
+}
+
+type syslogWriter struct {
+	writer *syslog.Writer
+	text   bool
+}
+
+func newSyslogSink(syslogConfig sink.Syslog, sinkFormat format.Format, level Level) (*logSink, error) {
+	facility := syslog.LOG_DAEMON
+	if syslogConfig.Facility != constants.EMPTY {
+		var ok bool
+
+		facility, ok = syslogFacilities[strings.ToLower(syslogConfig.Facility)]
+		if !ok {
+			return nil, errors.New("Unknown syslog facility: " + syslogConfig.Facility)
+		}
+	}
+
+	tag := syslogConfig.Tag
+	if tag == constants.EMPTY {
+		tag = DEFAULT_SYSLOG_TAG
+	}
+
+	// an empty network connects to the local syslog/journald socket
+	writer, err := syslog.Dial(syslogConfig.Network, syslogConfig.Address, facility|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, errors.New("Error on connecting to syslog: " + err.Error())
+	}
+
+	if sinkFormat != format.TEXT {
+		sinkFormat = format.JSON
+	}
+
+	output := &syslogWriter{
+		writer: writer,
+		text:   sinkFormat == format.TEXT,
+	}
+
+	return &logSink{
+		logger: zerolog.New(output),
+		format: sinkFormat,
+		level:  level,
+		closer: writer,
+	}, nil
+}
+
+func (output *syslogWriter) Write(data []byte) (int, error) {
+	return output.WriteLevel(zerolog.NoLevel, data)
+}
+
+func (output *syslogWriter) WriteLevel(level zerolog.Level, data []byte) (int, error) {
+	message := string(data)
+
+	if output.text {
+		var buffer bytes.Buffer
+
+		// syslog stamps the time itself
+		console := zerolog.ConsoleWriter{
+			Out:          &buffer,
+			NoColor:      true,
+			PartsExclude: []string{zerolog.TimestampFieldName},
+		}
+
+		_, err := console.Write(data)
+		if err != nil {
+			return 0, err
+		}
+
+		message = buffer.String()
+	}
+
+	var err error
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		err = output.writer.Debug(message)
+	case zerolog.WarnLevel:
+		err = output.writer.Warning(message)
+	case zerolog.ErrorLevel:
+		err = output.writer.Err(message)
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		err = output.writer.Crit(message)
+	default:
+		err = output.writer.Info(message)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
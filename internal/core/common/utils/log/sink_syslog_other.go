@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package log
+
+import (
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/format"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/sink"
+)
+
+func newSyslogSink(syslogConfig sink.Syslog, sinkFormat format.Format, level Level) (*logSink, error) {
+	return nil, errors.New("Syslog log sink is not supported on this platform")
+}
@@ -0,0 +1,225 @@
+package rotate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BACKUP_TIME_FORMAT = "20060102T150405.000"
+
+	DEFAULT_MAX_SIZE = 100 * 1024 * 1024
+	FILE_MODE        = 0644
+	DIR_MODE         = 0755
+)
+
+type Writer struct {
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxAge     time.Duration
+	maxBackups int
+	file       *os.File
+	size       int64
+	openedAt   time.Time
+	mutex      sync.Mutex
+}
+
+func NewWriter(path string, maxSize int64, interval time.Duration, maxAge time.Duration, maxBackups int) (*Writer, error) {
+	if len(path) == 0 {
+		return nil, errors.New("Rotating file requires a path")
+	}
+
+	if maxSize <= 0 {
+		maxSize = DEFAULT_MAX_SIZE
+	}
+
+	writer := &Writer{
+		path:       path,
+		maxSize:    maxSize,
+		interval:   interval,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+	}
+
+	err := writer.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (writer *Writer) Path() string {
+	return writer.path
+}
+
+func (writer *Writer) Write(data []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	var rotateErr error
+	if writer.shouldRotate(int64(len(data))) {
+		rotateErr = writer.rotate()
+	}
+
+	if writer.file == nil {
+		err := writer.open()
+		if err != nil {
+			return 0, errors.Join(rotateErr, err)
+		}
+	}
+
+	// a failed rotation keeps appending to the current file and is still reported
+	n, err := writer.file.Write(data)
+	writer.size += int64(n)
+
+	return n, errors.Join(rotateErr, err)
+}
+
+func (writer *Writer) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	if writer.file == nil {
+		return nil
+	}
+
+	err := writer.file.Close()
+	writer.file = nil
+
+	return err
+}
+
+func (writer *Writer) shouldRotate(length int64) bool {
+	if writer.size > 0 && writer.size+length > writer.maxSize {
+		return true
+	}
+
+	return writer.interval > 0 && time.Since(writer.openedAt) >= writer.interval
+}
+
+func (writer *Writer) open() error {
+	err := os.MkdirAll(filepath.Dir(writer.path), DIR_MODE)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(writer.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, FILE_MODE)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	writer.file = file
+	writer.size = info.Size()
+	writer.openedAt = time.Now()
+
+	return nil
+}
+
+func (writer *Writer) rotate() error {
+	if writer.file != nil {
+		err := writer.file.Close()
+		writer.file = nil
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.Rename(writer.path, writer.backupPath(time.Now()))
+	if err != nil && !os.IsNotExist(err) {
+		// the original file is reopened by the caller, resetting the interval
+		return err
+	}
+
+	err = writer.open()
+	if err != nil {
+		return err
+	}
+
+	writer.removeOldBackups()
+	return nil
+}
+
+func (writer *Writer) backupPath(now time.Time) string {
+	extension := filepath.Ext(writer.path)
+	prefix := strings.TrimSuffix(writer.path, extension)
+
+	return prefix + "-" + now.Format(BACKUP_TIME_FORMAT) + extension
+}
+
+func (writer *Writer) backups() []string {
+	return backups(writer.path)
+}
+
+// Files lists the rotated backups of a path followed by the current file, oldest first
+func Files(path string) []string {
+	files := backups(path)
+
+	_, err := os.Stat(path)
+	if err == nil {
+		files = append(files, path)
+	}
+
+	return files
+}
+
+func backups(path string) []string {
+	extension := filepath.Ext(path)
+	prefix := strings.TrimSuffix(path, extension)
+
+	matches, err := filepath.Glob(prefix + "-*" + extension)
+	if err != nil {
+		return nil
+	}
+
+	// the glob also matches files of other sinks sharing the prefix, like helper-audit.log
+	var backups []string
+	for _, match := range matches {
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(match, prefix+"-"), extension)
+
+		_, err = time.Parse(BACKUP_TIME_FORMAT, timestamp)
+		if err == nil {
+			backups = append(backups, match)
+		}
+	}
+
+	// backup names embed a sortable timestamp, newest last
+	sort.Strings(backups)
+	return backups
+}
+
+func (writer *Writer) removeOldBackups() {
+	backups := writer.backups()
+
+	if writer.maxBackups > 0 && len(backups) > writer.maxBackups {
+		for _, backup := range backups[:len(backups)-writer.maxBackups] {
+			os.Remove(backup)
+		}
+
+		backups = backups[len(backups)-writer.maxBackups:]
+	}
+
+	if writer.maxAge > 0 {
+		cutoff := time.Now().Add(-writer.maxAge)
+
+		for _, backup := range backups {
+			info, err := os.Stat(backup)
+			if err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(backup)
+			}
+		}
+	}
+}
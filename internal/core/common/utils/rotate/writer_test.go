@@ -0,0 +1,204 @@
+package rotate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writtenFiles lists the backups of a writer followed by its current file, oldest first
+func writtenFiles(writer *Writer) []string {
+	return append(writer.backups(), writer.Path())
+}
+
+func readAll(t *testing.T, files []string) string {
+	var builder strings.Builder
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		builder.Write(data)
+	}
+
+	return builder.String()
+}
+
+func TestWriterRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+
+	writer, err := NewWriter(path, 10, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		_, err = writer.Write([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// backup names have millisecond resolution
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	files := writtenFiles(writer)
+	if len(files) != 3 || files[len(files)-1] != path {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	if content := readAll(t, files); content != "first\nsecond\nthird\n" {
+		t.Errorf("files out of order: %q", content)
+	}
+}
+
+func TestWriterKeepsMaxBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	writer, err := NewWriter(path, 1, 0, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	for _, line := range []string{"1", "2", "3", "4", "5"} {
+		_, err = writer.Write([]byte(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	files := writtenFiles(writer)
+	if content := readAll(t, files); len(files) != 3 || content != "345" {
+		t.Errorf("expected the newest 2 backups and the current file, got %v with %q", files, content)
+	}
+}
+
+func TestWriterRotatesByInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	writer, err := NewWriter(path, 0, 10*time.Millisecond, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	writer.Write([]byte("a"))
+	writer.Write([]byte("b"))
+	time.Sleep(15 * time.Millisecond)
+	writer.Write([]byte("c"))
+
+	if files := writtenFiles(writer); len(files) != 2 || readAll(t, files) != "abc" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
+
+func TestWriterAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	err := os.WriteFile(path, []byte("12345"), FILE_MODE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer, err := NewWriter(path, 8, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	writer.Write([]byte("67"))
+	writer.Write([]byte("890"))
+
+	files := writtenFiles(writer)
+	if len(files) != 2 || readAll(t, files) != "1234567890" {
+		t.Errorf("existing size not accounted, files %v", files)
+	}
+}
+
+func TestBackupPath(t *testing.T) {
+	writer := &Writer{path: "logs/audit.jsonl"}
+	now := time.Date(2024, time.May, 1, 10, 20, 30, 456000000, time.UTC)
+
+	if backup := writer.backupPath(now); backup != "logs/audit-20240501T102030.456.jsonl" {
+		t.Errorf("backupPath = %s", backup)
+	}
+}
+
+func TestNewWriterRequiresPath(t *testing.T) {
+	if _, err := NewWriter("", 0, 0, 0, 0); err == nil {
+		t.Error("writer without path created")
+	}
+}
+
+func TestWriterIgnoresFilesOfOtherSinks(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "helper.log")
+	siblings := []string{filepath.Join(directory, "helper-audit.log"), filepath.Join(directory, "helper-2024.log")}
+
+	for _, sibling := range siblings {
+		err := os.WriteFile(sibling, []byte("other"), FILE_MODE)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writer, err := NewWriter(path, 1, 0, time.Nanosecond, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	for _, line := range []string{"1", "2", "3"} {
+		writer.Write([]byte(line))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	for _, sibling := range siblings {
+		if _, err = os.Stat(sibling); err != nil {
+			t.Errorf("sibling %s removed by retention: %v", sibling, err)
+		}
+	}
+
+	for _, file := range Files(path) {
+		if strings.Contains(file, "audit") || strings.Contains(file, "2024") {
+			t.Errorf("sibling %s listed as history", file)
+		}
+	}
+}
+
+func TestWriterRecoversFromFailedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	writer, err := NewWriter(path, 1, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	writer.Write([]byte("a"))
+
+	// closing the file behind the writer makes the next rotation fail
+	writer.file.Close()
+
+	n, err := writer.Write([]byte("b"))
+	if err == nil || n != 1 {
+		t.Fatalf("failed rotation returned %d, %v", n, err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+
+	if _, err = writer.Write([]byte("c")); err != nil {
+		t.Fatalf("write after failed rotation: %v", err)
+	}
+
+	if files := Files(path); len(files) != 2 || readAll(t, files) != "abc" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
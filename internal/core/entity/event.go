@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/event"
+	"fmt"
+	"time"
+)
+
+type Event struct {
+	Type    event.Type        `json:"type"`
+	Time    time.Time         `json:"time"`
+	Group   string            `json:"group,omitempty"`
+	Link    string            `json:"link,omitempty"`
+	Message string            `json:"message"`
+	Code    string            `json:"code,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func NewEvent(eventType event.Type, message string) Event {
+	return Event{
+		Type:    eventType,
+		Time:    time.Now(),
+		Message: message,
+		Details: make(map[string]string),
+	}
+}
+
+func (event Event) Title() string {
+	if event.Group != "" {
+		return fmt.Sprintf("[%s] %s", event.Group, event.Type)
+	}
+
+	if event.Link != "" {
+		return fmt.Sprintf("[%s] %s", event.Link, event.Type)
+	}
+
+	return string(event.Type)
+}
+
+// Key identifies events carrying the same information, used for de-duplication
+func (event Event) Key() string {
+	return fmt.Sprintf("%s|%s|%s|%s", event.Type, event.Group, event.Link, event.Message)
+}
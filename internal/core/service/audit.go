@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/audit"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/action"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+const (
+	TRIGGER_IP_CHANGE          = "ip-change"
+	TRIGGER_FALLBACK_ENABLE    = "fallback-enable"
+	TRIGGER_FALLBACK_DISABLE   = "fallback-disable"
+	TRIGGER_SCHEDULED_CAPACITY = "scheduled-capacity"
+	TRIGGER_MANUAL             = "manual"
+	TRIGGER_UNKNOWN            = "unknown"
+)
+
+func (service *HelperService) audit(ctx *context.Context, actionType action.Type, resource string, oldValue string, newValue string, errw *exceptions.WrappedError) {
+	entry := audit.Entry{
+		Trigger:    traceString(ctx, log.TRACE_TRIGGER),
+		Actor:      traceString(ctx, log.TRACE_PRINCIPAL),
+		Group:      traceString(ctx, log.TRACE_GROUP),
+		Link:       traceString(ctx, log.TRACE_LINK),
+		CycleId:    traceString(ctx, log.TRACE_CYCLE_ID),
+		FailoverId: traceString(ctx, log.TRACE_FAILOVER_ID),
+		Action:     string(actionType),
+		Resource:   resource,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+		Result:     audit.RESULT_SUCCESS,
+	}
+
+	if entry.Trigger == "" {
+		entry.Trigger = TRIGGER_UNKNOWN
+	}
+
+	if errw != nil {
+		entry.Result = audit.RESULT_FAILURE
+		entry.Code = string(errw.GetCode())
+		entry.Error = errw.GetMessage()
+	}
+
+	err := service.journal.Record(entry)
+	if err != nil {
+		log.Error(ctx).Msg("Error on writing audit entry: " + err.Error())
+	}
+}
+
+func (service *HelperService) GetAuditEntries(filter audit.Filter) ([]audit.Entry, error) {
+	return audit.Query(audit.Path(config.ApplicationConfig.Audit), filter)
+}
+
+func traceString(ctx *context.Context, key string) string {
+	value := log.GetTrace(ctx, key)
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// currentRecordValue is best effort, the audit entry is written without old value when it can't be read
+func currentRecordValue(ctx *context.Context, client *route53.Client, hostedZoneId string, recordName string, rrtype route53types.RRType) string {
+	output, err := client.ListResourceRecordSets(*ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(hostedZoneId),
+		StartRecordName: aws.String(recordName),
+		StartRecordType: rrtype,
+		MaxItems:        aws.Int32(1),
+	})
+	if err != nil || len(output.ResourceRecordSets) == 0 {
+		return ""
+	}
+
+	recordSet := output.ResourceRecordSets[0]
+	if recordSet.Type != rrtype || strings.TrimSuffix(aws.ToString(recordSet.Name), ".") != strings.TrimSuffix(recordName, ".") {
+		return ""
+	}
+
+	var values []string
+	for _, record := range recordSet.ResourceRecords {
+		values = append(values, aws.ToString(record.Value))
+	}
+
+	return strings.Join(values, ",")
+}
+
+func currentCapacity(ctx *context.Context, client *autoscaling.Client, autoscalingGroupName string) string {
+	output, err := client.DescribeAutoScalingGroups(*ctx, &autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []string{autoscalingGroupName},
+	})
+	if err != nil || len(output.AutoScalingGroups) == 0 {
+		return ""
+	}
+
+	autoScalingGroup := output.AutoScalingGroups[0]
+	return formatCapacity(aws.ToInt32(autoScalingGroup.MinSize), aws.ToInt32(autoScalingGroup.MaxSize), aws.ToInt32(autoScalingGroup.DesiredCapacity))
+}
+
+func formatCapacity(minSize int32, maxSize int32, desired int32) string {
+	return fmt.Sprintf("min=%d max=%d desired=%d", minSize, maxSize, desired)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	AWS_RETRY_MODE_STANDARD = "standard"
+	AWS_RETRY_MODE_ADAPTIVE = "adaptive"
+
+	TRACE_AWS_SERVICE    = "awsService"
+	TRACE_AWS_OPERATION  = "awsOperation"
+	TRACE_AWS_REQUEST_ID = "awsRequestId"
+	TRACE_AWS_ERROR_CODE = "awsErrorCode"
+	TRACE_AWS_STATUS     = "awsStatus"
+)
+
+func newAwsRetryer(retryConfig config.AwsRetry) (func() aws.Retryer, error) {
+	standardOptions := func(options *retry.StandardOptions) {
+		if retryConfig.MaxAttempts > 0 {
+			options.MaxAttempts = retryConfig.MaxAttempts
+		}
+
+		if retryConfig.MaxBackoff > 0 {
+			options.MaxBackoff = retryConfig.MaxBackoff
+			options.Backoff = retry.NewExponentialJitterBackoff(retryConfig.MaxBackoff)
+		}
+	}
+
+	switch strings.ToLower(retryConfig.Mode) {
+	case AWS_RETRY_MODE_STANDARD, "":
+		return func() aws.Retryer {
+			return retry.NewStandard(standardOptions)
+		}, nil
+
+	case AWS_RETRY_MODE_ADAPTIVE:
+		return func() aws.Retryer {
+			return retry.NewAdaptiveMode(func(options *retry.AdaptiveModeOptions) {
+				options.StandardOptions = append(options.StandardOptions, standardOptions)
+			})
+		}, nil
+	}
+
+	return nil, errors.New("Unknown AWS retry mode: " + retryConfig.Mode)
+}
+
+func wrapAwsError(ctx *context.Context, err error) *exceptions.WrappedError {
+	errorCtx := ctx
+	errorCode := ""
+
+	var operationError *smithy.OperationError
+	if errors.As(err, &operationError) {
+		errorCtx = log.WithTrace(errorCtx, TRACE_AWS_SERVICE, operationError.Service())
+		errorCtx = log.WithTrace(errorCtx, TRACE_AWS_OPERATION, operationError.Operation())
+	}
+
+	var responseError *awshttp.ResponseError
+	if errors.As(err, &responseError) {
+		errorCtx = log.WithTrace(errorCtx, TRACE_AWS_REQUEST_ID, responseError.ServiceRequestID())
+		errorCtx = log.WithTrace(errorCtx, TRACE_AWS_STATUS, responseError.HTTPStatusCode())
+	}
+
+	var apiError smithy.APIError
+	if errors.As(err, &apiError) {
+		errorCode = apiError.ErrorCode()
+		errorCtx = log.WithTrace(errorCtx, TRACE_AWS_ERROR_CODE, errorCode)
+	}
+
+	log.Error(errorCtx).Msg("AWS call failed: " + err.Error())
+
+	return &exceptions.WrappedError{
+		Err:  err,
+		Code: exceptions.Classify(err),
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"sync"
+	"time"
+)
+
+type dnsLink struct {
+	config     config.DNSLink
+	httpClient *api.HttpClient
+	publicIp   string
+	fetchError string
+	lastCheck  *time.Time
+	zones      map[string]HostedZoneStatus
+	mutex      sync.RWMutex
+}
+
+type DNSLinkStatus struct {
+	Name        string             `json:"name"`
+	Record      string             `json:"record"`
+	PublicIp    string             `json:"publicIp,omitempty"`
+	FetchError  string             `json:"fetchError,omitempty"`
+	LastCheck   *time.Time         `json:"lastCheck,omitempty"`
+	HostedZones []HostedZoneStatus `json:"hostedZones"`
+}
+
+type HostedZoneStatus struct {
+	HostedZoneId string     `json:"hostedZoneId"`
+	Synced       bool       `json:"synced"`
+	Value        string     `json:"value,omitempty"`
+	LastSync     *time.Time `json:"lastSync,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+func newDNSLink(linkConfig config.DNSLink, httpClient *api.HttpClient) *dnsLink {
+	return &dnsLink{
+		config:     linkConfig,
+		httpClient: httpClient,
+		zones:      make(map[string]HostedZoneStatus),
+	}
+}
+
+func (link *dnsLink) registerPublicIp(publicIp string) {
+	link.mutex.Lock()
+	defer link.mutex.Unlock()
+
+	now := time.Now()
+	link.lastCheck = &now
+	link.publicIp = publicIp
+	link.fetchError = ""
+}
+
+func (link *dnsLink) registerFetchError(errw *exceptions.WrappedError) {
+	link.mutex.Lock()
+	defer link.mutex.Unlock()
+
+	now := time.Now()
+	link.lastCheck = &now
+	link.fetchError = errw.GetMessage()
+}
+
+// registerZoneSync keeps the last synced value and time of a zone when an update fails
+func (link *dnsLink) registerZoneSync(hostedZoneId string, value string, errw *exceptions.WrappedError) {
+	link.mutex.Lock()
+	defer link.mutex.Unlock()
+
+	zone := link.zones[hostedZoneId]
+	zone.HostedZoneId = hostedZoneId
+
+	if errw != nil {
+		zone.Synced = false
+		zone.Error = errw.GetMessage()
+	} else {
+		now := time.Now()
+		zone.Synced = true
+		zone.Value = value
+		zone.LastSync = &now
+		zone.Error = ""
+	}
+
+	link.zones[hostedZoneId] = zone
+}
+
+func (link *dnsLink) status() DNSLinkStatus {
+	link.mutex.RLock()
+	defer link.mutex.RUnlock()
+
+	hostedZones := make([]HostedZoneStatus, 0, len(link.config.Record.HostedZoneIds))
+	for _, hostedZoneId := range link.config.Record.HostedZoneIds {
+		zone, exists := link.zones[hostedZoneId]
+		if !exists {
+			zone = HostedZoneStatus{HostedZoneId: hostedZoneId}
+		}
+
+		hostedZones = append(hostedZones, zone)
+	}
+
+	return DNSLinkStatus{
+		Name:        link.config.Name,
+		Record:      link.config.Record.Name,
+		PublicIp:    link.publicIp,
+		FetchError:  link.fetchError,
+		LastCheck:   link.lastCheck,
+		HostedZones: hostedZones,
+	}
+}
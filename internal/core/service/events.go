@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/entity"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/event"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/scheduler"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emit sends an event to the notification channels and, when enabled, publishes it to SNS/EventBridge
+func (service *HelperService) emit(ctx *context.Context, helperEvent entity.Event) {
+	service.notifier.Notify(ctx, helperEvent)
+
+	if !service.publisher.IsEnabled(helperEvent.Type) {
+		return
+	}
+
+	go func() {
+		awsConfig, errw := service.getAWSConfig(ctx)
+		if errw != nil {
+			log.Error(ctx).Msg(fmt.Sprintf("Error on getting AWS config: %v", errw.GetMessage()))
+			return
+		}
+
+		err := service.publisher.Publish(ctx, awsConfig, helperEvent)
+		if err != nil {
+			errw = wrapAwsError(ctx, err)
+			log.Error(ctx).Msg(fmt.Sprintf("Error on publishing event %s: %v", helperEvent.Type, errw.GetMessage()))
+		}
+	}()
+}
+
+func (service *HelperService) notifyIpChanged(ctx *context.Context, link *dnsLink, previousIp string, publicIp string) {
+	ipEvent := entity.NewEvent(event.IP_CHANGED, fmt.Sprintf("Public IP of %s changed from %s to %s", link.config.Record.Name, previousIp, publicIp))
+	ipEvent.Link = link.config.Name
+	ipEvent.Details["record"] = link.config.Record.Name
+	ipEvent.Details["previousIp"] = previousIp
+	ipEvent.Details["publicIp"] = publicIp
+
+	service.metrics.RecordIpChange(link.config.Name)
+	service.emit(ctx, ipEvent)
+}
+
+func (service *HelperService) notifyDnsUpdateFailed(ctx *context.Context, link *dnsLink, errw *exceptions.WrappedError) {
+	if !errw.ShouldAlert() {
+		return
+	}
+
+	dnsEvent := entity.NewEvent(event.DNS_UPDATE_FAILED, fmt.Sprintf("Error on updating DNS %s: %s", link.config.Record.Name, errw.GetMessage()))
+	dnsEvent.Link = link.config.Name
+	dnsEvent.Code = string(errw.GetCode())
+	dnsEvent.Details["record"] = link.config.Record.Name
+
+	service.emit(ctx, dnsEvent)
+}
+
+func (service *HelperService) notifyFallback(ctx *context.Context, group *fallbackGroup, fallback bool, errw *exceptions.WrappedError) {
+	var fallbackEvent entity.Event
+
+	if errw != nil {
+		fallbackEvent = entity.NewEvent(event.FALLBACK_FAILED, fmt.Sprintf("Error on changing ISP fallback of group %s to %t: %s", group.name(), fallback, errw.GetMessage()))
+		fallbackEvent.Code = string(errw.GetCode())
+	} else if fallback {
+		fallbackEvent = entity.NewEvent(event.FALLBACK_ENABLED, fmt.Sprintf("ISP of group %s is down, fallback enabled", group.name()))
+	} else {
+		fallbackEvent = entity.NewEvent(event.FALLBACK_DISABLED, fmt.Sprintf("ISP of group %s recovered, fallback disabled", group.name()))
+	}
+
+	fallbackEvent.Group = group.name()
+	fallbackEvent.Details["fallback"] = fmt.Sprintf("%t", fallback)
+
+	var failedProbes []string
+	for _, result := range group.status().ProbeResults {
+		if !result.Success {
+			failedProbes = append(failedProbes, fmt.Sprintf("%s (%s)", result.Probe, result.Class))
+		}
+	}
+
+	if len(failedProbes) > 0 {
+		fallbackEvent.Details["failedProbes"] = strings.Join(failedProbes, ", ")
+	}
+
+	service.emit(ctx, fallbackEvent)
+}
+
+func (service *HelperService) notifyFallbackSuppressed(ctx *context.Context, group *fallbackGroup, quietWindow *scheduler.QuietWindow, fallback bool) {
+	suppressedEvent := entity.NewEvent(event.FALLBACK_SUPPRESSED, fmt.Sprintf("Fallback change of group %s to %t suppressed by quiet window %s", group.name(), fallback, quietWindow.Name()))
+	suppressedEvent.Group = group.name()
+	suppressedEvent.Details["fallback"] = fmt.Sprintf("%t", fallback)
+	suppressedEvent.Details["quietWindow"] = quietWindow.Name()
+
+	service.emit(ctx, suppressedEvent)
+}
+
+func (service *HelperService) notifyCapacityScheduled(ctx *context.Context, group *fallbackGroup, scheduled scheduledCapacity) {
+	asgEvent := entity.NewEvent(event.ASG_SCHEDULED, fmt.Sprintf("Auto scaling group %s scheduled to capacity %d at %s", scheduled.autoScalingGroup, scheduled.capacity, scheduled.executeAt.Format(time.RFC3339)))
+	asgEvent.Group = group.name()
+	addCapacityDetails(&asgEvent, scheduled)
+
+	service.emit(ctx, asgEvent)
+}
+
+func (service *HelperService) notifyCapacityApplied(ctx *context.Context, group *fallbackGroup, scheduled scheduledCapacity) {
+	// asg-shut-down only when the group goes to zero, any other capacity is a scale
+	eventType := event.ASG_SHUT_DOWN
+	if scheduled.capacity > 0 {
+		eventType = event.ASG_SCALED
+	}
+
+	asgEvent := entity.NewEvent(eventType, fmt.Sprintf("Auto scaling group %s updated to scheduled capacity %d", scheduled.autoScalingGroup, scheduled.capacity))
+	asgEvent.Group = group.name()
+	addCapacityDetails(&asgEvent, scheduled)
+
+	service.emit(ctx, asgEvent)
+}
+
+func (service *HelperService) notifyCapacityCancelled(ctx *context.Context, group *fallbackGroup, scheduled scheduledCapacity, reason string) {
+	asgEvent := entity.NewEvent(event.ASG_CANCELLED, fmt.Sprintf("Scheduled capacity %d of auto scaling group %s cancelled by %s", scheduled.capacity, scheduled.autoScalingGroup, reason))
+	asgEvent.Group = group.name()
+	addCapacityDetails(&asgEvent, scheduled)
+	asgEvent.Details["reason"] = reason
+
+	service.emit(ctx, asgEvent)
+}
+
+func addCapacityDetails(asgEvent *entity.Event, scheduled scheduledCapacity) {
+	asgEvent.Details["scheduleId"] = scheduled.id
+	asgEvent.Details["autoScalingGroup"] = scheduled.autoScalingGroup
+	asgEvent.Details["capacity"] = strconv.Itoa(int(scheduled.capacity))
+	asgEvent.Details["executeAt"] = scheduled.executeAt.Format(time.RFC3339)
+	asgEvent.Details["extensions"] = strconv.Itoa(scheduled.extensions)
+}
+
+func (service *HelperService) notifyActionFailed(ctx *context.Context, group *fallbackGroup, fallbackAction config.FallbackAction, errw *exceptions.WrappedError) {
+	actionEvent := entity.NewEvent(event.ACTION_FAILED, fmt.Sprintf("Action %s of group %s failed: %s", fallbackAction.Type, group.name(), errw.GetMessage()))
+	actionEvent.Group = group.name()
+	actionEvent.Code = string(errw.GetCode())
+	actionEvent.Details["action"] = string(fallbackAction.Type)
+	actionEvent.Details["continueOnError"] = strconv.FormatBool(fallbackAction.ContinueOnError)
+
+	service.emit(ctx, actionEvent)
+}
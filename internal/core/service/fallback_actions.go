@@ -0,0 +1,396 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/action"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+const (
+	EC2_STATE_RUNNING = "running"
+	EC2_STATE_STOPPED = "stopped"
+
+	// delays hold the failover lock of the group, so they are kept short
+	MAX_DELAY = 10 * time.Minute
+)
+
+type webhookTemplateData struct {
+	Group    string
+	Fallback bool
+	Time     time.Time
+}
+
+func (service *HelperService) executeActions(ctx *context.Context, group *fallbackGroup, actions []config.FallbackAction, fallback bool) *exceptions.WrappedError {
+	var awsConfig *aws.Config
+
+	for index, fallbackAction := range actions {
+		log.Info(ctx).Msg(fmt.Sprintf("Executing action %d/%d: %s", index+1, len(actions), fallbackAction.Type))
+
+		if isAwsAction(fallbackAction.Type) && awsConfig == nil {
+			var errw *exceptions.WrappedError
+
+			awsConfig, errw = service.getAWSConfig(ctx)
+			if errw != nil {
+				return errw
+			}
+		}
+
+		errw := service.executeAction(ctx, group, awsConfig, fallbackAction, fallback)
+		if errw != nil {
+			service.notifyActionFailed(ctx, group, fallbackAction, errw)
+
+			if !fallbackAction.ContinueOnError || errw.ShouldAbort() {
+				return errw
+			}
+
+			log.Warn(ctx).Msg(fmt.Sprintf("Ignoring error on action %s: %v", fallbackAction.Type, errw.GetMessage()))
+		}
+	}
+
+	return nil
+}
+
+func (service *HelperService) executeAction(ctx *context.Context, group *fallbackGroup, awsConfig *aws.Config, fallbackAction config.FallbackAction, fallback bool) *exceptions.WrappedError {
+	switch fallbackAction.Type {
+	case action.ROUTE53_RECORD:
+		return service.executeRecordAction(ctx, awsConfig, fallbackAction)
+
+	case action.CLOUDFRONT_ORIGIN:
+		cloudfront := fallbackAction.Cloudfront
+		return service.updateCloudfrontDistribution(ctx, awsConfig, cloudfront.DistributionId, cloudfront.Origin)
+
+	case action.ASG_CAPACITY:
+		return service.executeCapacityAction(ctx, group, awsConfig, fallbackAction, fallback)
+
+	case action.EC2_INSTANCE:
+		ec2Instance := fallbackAction.EC2Instance
+		return service.updateInstancesState(ctx, awsConfig, ec2Instance.InstanceIds, ec2Instance.State)
+
+	case action.WEBHOOK:
+		return service.executeWebhookAction(ctx, group, fallbackAction, fallback)
+
+	case action.DELAY:
+		return waitDelay(ctx, fallbackAction.Delay.Duration)
+	}
+
+	return &exceptions.WrappedError{
+		Err:  errors.New("Unknown action type: " + string(fallbackAction.Type)),
+		Code: exceptions.CONFIG,
+	}
+}
+
+// waitDelay holds the failover of the group, so it stops as soon as the context is done
+func waitDelay(ctx *context.Context, duration time.Duration) *exceptions.WrappedError {
+	log.Info(ctx).Msg(fmt.Sprintf("Waiting %s", duration))
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-(*ctx).Done():
+		return &exceptions.WrappedError{
+			Err:  fmt.Errorf("Delay of %s interrupted: %w", duration, (*ctx).Err()),
+			Code: exceptions.CANCELED,
+		}
+	case <-timer.C:
+		return nil
+	}
+}
+
+// validateActions checks the actions at startup, so a mistake is not found only during an outage
+func validateActions(actions []config.FallbackAction, webhookApi *api.WebhookApi) error {
+	for index, fallbackAction := range actions {
+		err := validateAction(fallbackAction, webhookApi)
+		if err != nil {
+			return fmt.Errorf("Invalid action %d (%s): %w", index+1, fallbackAction.Type, err)
+		}
+	}
+
+	return nil
+}
+
+func validateAction(fallbackAction config.FallbackAction, webhookApi *api.WebhookApi) error {
+	switch fallbackAction.Type {
+	case action.ROUTE53_RECORD:
+		record := fallbackAction.Record
+		if len(record.HostedZoneIds) == 0 || utils.IsEmptyStr(record.Name) || utils.IsEmptyStr(record.Value) {
+			return errors.New("record requires hosted-zone-ids, name and value")
+		}
+
+	case action.CLOUDFRONT_ORIGIN:
+		cloudfront := fallbackAction.Cloudfront
+		if utils.IsEmptyStr(cloudfront.DistributionId) || utils.IsEmptyStr(cloudfront.Origin) {
+			return errors.New("cloudfront requires distribution-id and origin")
+		}
+
+	case action.ASG_CAPACITY:
+		autoScalingGroup := fallbackAction.AutoScalingGroup
+		if utils.IsEmptyStr(autoScalingGroup.Name) {
+			return errors.New("auto-scaling-group requires name")
+		}
+
+		if autoScalingGroup.Capacity < 0 || autoScalingGroup.After < 0 {
+			return errors.New("auto-scaling-group capacity and after must not be negative")
+		}
+
+	case action.EC2_INSTANCE:
+		ec2Instance := fallbackAction.EC2Instance
+		if len(ec2Instance.InstanceIds) == 0 {
+			return errors.New("ec2-instance requires instance-ids")
+		}
+
+		if ec2Instance.State != EC2_STATE_RUNNING && ec2Instance.State != EC2_STATE_STOPPED {
+			return fmt.Errorf("ec2-instance state must be %s or %s", EC2_STATE_RUNNING, EC2_STATE_STOPPED)
+		}
+
+	case action.WEBHOOK:
+		webhook := fallbackAction.Webhook
+		if utils.IsEmptyStr(webhook.Url) {
+			return errors.New("webhook requires url")
+		}
+
+		_, err := webhookApi.Client(webhook.Timeout, webhook.Client)
+		if err != nil {
+			return err
+		}
+
+	case action.DELAY:
+		duration := fallbackAction.Delay.Duration
+		if duration <= 0 || duration > MAX_DELAY {
+			return fmt.Errorf("delay duration must be between 0 and %s", MAX_DELAY)
+		}
+
+	default:
+		return errors.New("unknown action type")
+	}
+
+	return nil
+}
+
+func isAwsAction(actionType action.Type) bool {
+	return actionType == action.ROUTE53_RECORD || actionType == action.CLOUDFRONT_ORIGIN ||
+		actionType == action.ASG_CAPACITY || actionType == action.EC2_INSTANCE
+}
+
+func (service *HelperService) executeRecordAction(ctx *context.Context, awsConfig *aws.Config, fallbackAction config.FallbackAction) *exceptions.WrappedError {
+	record := fallbackAction.Record
+	rrType := route53types.RRTypeCname
+
+	if utils.IsNotEmptyStr(record.Type) {
+		rrType = route53types.RRType(strings.ToUpper(record.Type))
+	}
+
+	client := route53.NewFromConfig(*awsConfig)
+	for _, hostedZoneId := range record.HostedZoneIds {
+		errw := service.updateDNS(ctx, client, hostedZoneId, record.Name, record.Value, rrType, record.TTL)
+		if errw != nil {
+			return errw
+		}
+	}
+
+	return nil
+}
+
+func (service *HelperService) executeCapacityAction(ctx *context.Context, group *fallbackGroup, awsConfig *aws.Config, fallbackAction config.FallbackAction, fallback bool) *exceptions.WrappedError {
+	autoScalingGroup := fallbackAction.AutoScalingGroup
+
+	if autoScalingGroup.After > 0 {
+		now := time.Now()
+		scheduled, extended := group.scheduleCapacity(scheduledCapacity{
+			id:               log.NewTraceId(),
+			autoScalingGroup: autoScalingGroup.Name,
+			capacity:         autoScalingGroup.Capacity,
+			fallback:         fallback,
+			scheduledAt:      now,
+			executeAt:        now.Add(autoScalingGroup.After),
+		})
+
+		if extended {
+			log.Info(ctx).Msg(fmt.Sprintf("Extending scheduled capacity %d of auto scaling group %s to %s", scheduled.capacity, scheduled.autoScalingGroup, scheduled.executeAt))
+		} else {
+			log.Info(ctx).Msg(fmt.Sprintf("Updating auto scaling group %s to desired capacity %d at %s", scheduled.autoScalingGroup, scheduled.capacity, scheduled.executeAt))
+		}
+
+		service.notifyCapacityScheduled(ctx, group, scheduled)
+		return nil
+	}
+
+	// an immediate change supersedes a pending one of the same auto scaling group
+	if scheduled, removed := group.removeScheduledCapacity(autoScalingGroup.Name); removed {
+		log.Info(ctx).Msg(fmt.Sprintf("Replacing scheduled capacity %d of auto scaling group %s with an immediate update", scheduled.capacity, scheduled.autoScalingGroup))
+		service.notifyCapacityCancelled(ctx, group, scheduled, traceString(ctx, log.TRACE_TRIGGER))
+	}
+
+	return service.updateAutoScallingGroup(ctx, awsConfig, autoScalingGroup.Name, autoScalingGroup.Capacity)
+}
+
+// executeScheduledCapacity runs when the timer of a job fires, the job only applies while the group
+// is still in the fallback state that created it and this instance is the leader
+func (service *HelperService) executeScheduledCapacity(ctx *context.Context, group *fallbackGroup, id string) {
+	group.failoverMutex.Lock()
+	defer group.failoverMutex.Unlock()
+
+	scheduled, exists := group.findScheduledCapacity(id)
+	if !exists || time.Now().Before(scheduled.executeAt) {
+		return
+	}
+
+	ctx = log.WithTrace(ctx, log.TRACE_TRIGGER, TRIGGER_SCHEDULED_CAPACITY)
+
+	// the new leader schedules the job again when it applies the actions of the current fallback state
+	if !service.elector.IsLeader() {
+		log.Warn(ctx).Msg(fmt.Sprintf("Dropping scheduled capacity of %s, instance is no longer the leader", scheduled.autoScalingGroup))
+		group.removeScheduledCapacity(scheduled.autoScalingGroup)
+		service.notifyCapacityCancelled(ctx, group, scheduled, TRIGGER_SCHEDULED_CAPACITY)
+		return
+	}
+
+	fallback := group.status().Fallback
+	if fallback == nil {
+		log.Warn(ctx).Msg(fmt.Sprintf("Postponing scheduled capacity of %s, fallback state of group %s is unknown", scheduled.autoScalingGroup, group.name()))
+		group.retryCapacity(id, SCHEDULED_CAPACITY_RETRY_DELAY)
+		return
+	}
+
+	if *fallback != scheduled.fallback {
+		log.Warn(ctx).Msg(fmt.Sprintf("Cancelling scheduled capacity of %s, fallback of group %s changed to %t", scheduled.autoScalingGroup, group.name(), *fallback))
+		group.removeScheduledCapacity(scheduled.autoScalingGroup)
+		service.notifyCapacityCancelled(ctx, group, scheduled, TRIGGER_SCHEDULED_CAPACITY)
+		return
+	}
+
+	awsConfig, errw := service.getAWSConfig(ctx)
+	if errw == nil {
+		errw = service.updateAutoScallingGroup(ctx, awsConfig, scheduled.autoScalingGroup, scheduled.capacity)
+	}
+
+	if errw != nil {
+		log.Error(ctx).Msg(fmt.Sprintf("Error on updating Auto Scaling Group: %v", errw.GetMessage()))
+
+		if errw.IsRetryable() {
+			group.retryCapacity(id, SCHEDULED_CAPACITY_RETRY_DELAY)
+			return
+		}
+
+		log.Warn(ctx).Msg(fmt.Sprintf("Dropping scheduled capacity of %s, error %s is not retryable", scheduled.autoScalingGroup, errw.GetCode()))
+	} else {
+		service.notifyCapacityApplied(ctx, group, scheduled)
+	}
+
+	group.removeScheduledCapacity(scheduled.autoScalingGroup)
+}
+
+func (service *HelperService) executeWebhookAction(ctx *context.Context, group *fallbackGroup, fallbackAction config.FallbackAction, fallback bool) *exceptions.WrappedError {
+	webhook := fallbackAction.Webhook
+
+	body, err := renderWebhookBody(webhook.Body, webhookTemplateData{
+		Group:    group.name(),
+		Fallback: fallback,
+		Time:     time.Now(),
+	})
+	if err != nil {
+		return &exceptions.WrappedError{
+			Err: err,
+		}
+	}
+
+	log.Info(ctx).Msg(fmt.Sprintf("Calling webhook %s", webhook.Url))
+
+	erra := service.webhookApi.Call(ctx, webhook.Method, webhook.Url, webhook.Timeout, webhook.Client, webhook.Headers, body)
+	if erra != nil {
+		return erra.ToWrappedError(ctx)
+	}
+
+	return nil
+}
+
+func renderWebhookBody(body string, data webhookTemplateData) (string, error) {
+	if utils.IsEmptyStr(body) {
+		return body, nil
+	}
+
+	bodyTemplate, err := template.New("webhook").Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = bodyTemplate.Execute(&buffer, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func (service *HelperService) updateInstancesState(ctx *context.Context, awsConfig *aws.Config, instanceIds []string, state string) *exceptions.WrappedError {
+	log.Info(ctx).Msg(fmt.Sprintf("Changing EC2 instances %v to state %s", instanceIds, state))
+
+	client := ec2.NewFromConfig(*awsConfig)
+	resource := strings.Join(instanceIds, ",")
+
+	var stateChanges []ec2types.InstanceStateChange
+	var err error
+
+	switch strings.ToLower(state) {
+	case EC2_STATE_RUNNING:
+		var output *ec2.StartInstancesOutput
+
+		output, err = client.StartInstances(*ctx, &ec2.StartInstancesInput{
+			InstanceIds: instanceIds,
+		})
+		if err == nil {
+			stateChanges = output.StartingInstances
+		}
+
+	case EC2_STATE_STOPPED:
+		var output *ec2.StopInstancesOutput
+
+		output, err = client.StopInstances(*ctx, &ec2.StopInstancesInput{
+			InstanceIds: instanceIds,
+		})
+		if err == nil {
+			stateChanges = output.StoppingInstances
+		}
+
+	default:
+		return &exceptions.WrappedError{
+			Err:  errors.New("Unknown EC2 instance state: " + state),
+			Code: exceptions.CONFIG,
+		}
+	}
+
+	if err != nil {
+		errw := wrapAwsError(ctx, err)
+		service.audit(ctx, action.EC2_INSTANCE, resource, "", state, errw)
+
+		return errw
+	}
+
+	var previousStates []string
+	for _, stateChange := range stateChanges {
+		if stateChange.PreviousState != nil {
+			previousStates = append(previousStates, fmt.Sprintf("%s=%s", aws.ToString(stateChange.InstanceId), stateChange.PreviousState.Name))
+		}
+	}
+
+	service.audit(ctx, action.EC2_INSTANCE, resource, strings.Join(previousStates, ","), state, nil)
+	log.Info(ctx).Msg(fmt.Sprintf("Changed EC2 instances %v to state %s", instanceIds, state))
+	return nil
+}
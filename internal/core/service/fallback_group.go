@@ -0,0 +1,246 @@
+package service
+
+import (
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/prober"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/scheduler"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const LATENCY_HISTORY_SIZE = 60
+
+type fallbackGroup struct {
+	config               config.FallbackGroup
+	probes               []prober.Probe
+	scheduler            *scheduler.Scheduler
+	quietWindows         []*scheduler.QuietWindow
+	suppressed           *bool
+	ispFallback          *bool
+	leader               bool
+	down                 bool
+	probeResults         []prober.Result
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	lastCheck            *time.Time
+	scheduledCapacities  map[string]scheduledCapacity
+	capacityDue          func(id string)
+	latencies            map[string][]LatencyPoint
+	mutex                sync.RWMutex
+	failoverMutex        sync.Mutex
+}
+
+type FallbackGroupStatus struct {
+	Name                 string                    `json:"name"`
+	Fallback             *bool                     `json:"fallback"`
+	Down                 bool                      `json:"down"`
+	ProbeResults         []prober.Result           `json:"probeResults"`
+	ConsecutiveFailures  int                       `json:"consecutiveFailures"`
+	ConsecutiveSuccesses int                       `json:"consecutiveSuccesses"`
+	LastCheck            *time.Time                `json:"lastCheck,omitempty"`
+	QuietWindow          string                    `json:"quietWindow,omitempty"`
+	ScheduledCapacities  []ScheduledCapacityStatus `json:"scheduledCapacities"`
+	Latencies            map[string][]LatencyPoint `json:"latencies"`
+}
+
+type LatencyPoint struct {
+	Time      time.Time `json:"time"`
+	LatencyMs int64     `json:"latencyMs"`
+	Success   bool      `json:"success"`
+}
+
+// newFallbackGroups builds the configured groups, their names key the status, audit, uptime and metrics so they must be unique
+func newFallbackGroups(updaterConfig *config.ISPFallbackUpdater, webhookApi *api.WebhookApi) ([]*fallbackGroup, error) {
+	if updaterConfig == nil {
+		return nil, nil
+	}
+
+	if len(updaterConfig.Groups) == 0 {
+		return nil, errors.New("ISP fallback updater has no groups configured")
+	}
+
+	var fallbackGroups []*fallbackGroup
+	names := make(map[string]bool)
+
+	for index, groupConfig := range updaterConfig.Groups {
+		if utils.IsEmptyStr(groupConfig.Name) {
+			return nil, fmt.Errorf("Fallback group %d has no name", index+1)
+		}
+
+		if names[groupConfig.Name] {
+			return nil, errors.New("Duplicated fallback group name: " + groupConfig.Name)
+		}
+
+		names[groupConfig.Name] = true
+
+		group, err := newFallbackGroup(groupConfig, webhookApi)
+		if err != nil {
+			return nil, err
+		}
+
+		fallbackGroups = append(fallbackGroups, group)
+	}
+
+	return fallbackGroups, nil
+}
+
+func newFallbackGroup(groupConfig config.FallbackGroup, webhookApi *api.WebhookApi) (*fallbackGroup, error) {
+	quietWindows, err := scheduler.NewQuietWindows(groupConfig.QuietWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := groupConfig.Actions
+	for _, groupActions := range [][]config.FallbackAction{actions.Enable, actions.Disable} {
+		err = validateActions(groupActions, webhookApi)
+		if err != nil {
+			return nil, fmt.Errorf("Fallback group %s: %w", groupConfig.Name, err)
+		}
+	}
+
+	return &fallbackGroup{
+		config:              groupConfig,
+		quietWindows:        quietWindows,
+		scheduledCapacities: make(map[string]scheduledCapacity),
+		latencies:           make(map[string][]LatencyPoint),
+	}, nil
+}
+
+func (group *fallbackGroup) name() string {
+	return group.config.Name
+}
+
+func (group *fallbackGroup) failureThreshold() int {
+	return max(group.config.Thresholds.Failure, 1)
+}
+
+func (group *fallbackGroup) recoveryThreshold() int {
+	return max(group.config.Thresholds.Recovery, 1)
+}
+
+func (group *fallbackGroup) registerCheck(down bool, probeResults []prober.Result) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	now := time.Now()
+	group.lastCheck = &now
+	group.down = down
+	group.probeResults = probeResults
+
+	for _, result := range probeResults {
+		points := append(group.latencies[result.Probe], LatencyPoint{
+			Time:      now,
+			LatencyMs: result.Latency.Milliseconds(),
+			Success:   result.Success,
+		})
+
+		if len(points) > LATENCY_HISTORY_SIZE {
+			points = points[len(points)-LATENCY_HISTORY_SIZE:]
+		}
+
+		group.latencies[result.Probe] = points
+	}
+
+	if down {
+		group.consecutiveFailures++
+		group.consecutiveSuccesses = 0
+	} else {
+		group.consecutiveSuccesses++
+		group.consecutiveFailures = 0
+	}
+}
+
+func (group *fallbackGroup) shouldEnable() bool {
+	group.mutex.RLock()
+	defer group.mutex.RUnlock()
+
+	return (group.ispFallback == nil || !*group.ispFallback) && group.consecutiveFailures >= group.failureThreshold()
+}
+
+func (group *fallbackGroup) shouldDisable() bool {
+	group.mutex.RLock()
+	defer group.mutex.RUnlock()
+
+	return (group.ispFallback == nil || *group.ispFallback) && group.consecutiveSuccesses >= group.recoveryThreshold()
+}
+
+func (group *fallbackGroup) setFallback(fallback *bool) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	group.ispFallback = fallback
+}
+
+// observeLeadership forgets the fallback state when the instance becomes the leader, so the thresholds apply
+// the actions of the current state again and schedule anew the capacities the previous leader still had pending.
+// It returns the forgotten state
+func (group *fallbackGroup) observeLeadership(leader bool) *bool {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	promoted := leader && !group.leader
+	group.leader = leader
+
+	if !promoted {
+		return nil
+	}
+
+	forgotten := group.ispFallback
+	group.ispFallback = nil
+
+	return forgotten
+}
+
+// markSuppressed returns true only for the first suppression of a pending change
+func (group *fallbackGroup) markSuppressed(fallback bool) bool {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	if group.suppressed != nil && *group.suppressed == fallback {
+		return false
+	}
+
+	group.suppressed = &fallback
+	return true
+}
+
+func (group *fallbackGroup) clearSuppressed() {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	group.suppressed = nil
+}
+
+func (group *fallbackGroup) status() FallbackGroupStatus {
+	group.mutex.RLock()
+	defer group.mutex.RUnlock()
+
+	scheduledCapacities := group.scheduledCapacityStatuses()
+
+	quietWindow := ""
+	if window := scheduler.ActiveQuietWindow(group.quietWindows, time.Now()); window != nil {
+		quietWindow = window.Name()
+	}
+
+	latencies := make(map[string][]LatencyPoint, len(group.latencies))
+	for probeName, points := range group.latencies {
+		latencies[probeName] = append([]LatencyPoint(nil), points...)
+	}
+
+	return FallbackGroupStatus{
+		Name:                 group.name(),
+		Fallback:             group.ispFallback,
+		Down:                 group.down,
+		ProbeResults:         group.probeResults,
+		ConsecutiveFailures:  group.consecutiveFailures,
+		ConsecutiveSuccesses: group.consecutiveSuccesses,
+		LastCheck:            group.lastCheck,
+		QuietWindow:          quietWindow,
+		ScheduledCapacities:  scheduledCapacities,
+		Latencies:            latencies,
+	}
+}
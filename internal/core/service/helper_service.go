@@ -2,16 +2,31 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
 	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/audit"
 	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/action"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/quiet"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/election"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/metrics"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/network"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/notifier"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/prober"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/publisher"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/scheduler"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/uptime"
 	"fmt"
 	"net"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
@@ -20,142 +35,397 @@ import (
 	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 )
 
+const (
+	DEFAULT_LINK = "default"
+
+	DNS_UPDATER_LOOP  = "dns-updater"
+	ISP_FALLBACK_LOOP = "isp-fallback"
+)
+
 type HelperService struct {
-	fetcherApi                   *api.FetcherApi
-	ispFallback                  *bool
-	autoScalingGroupShutdownTime *time.Time
+	fetcherApi     *api.FetcherApi
+	agentApi       *api.AgentApi
+	webhookApi     *api.WebhookApi
+	notifier       *notifier.Notifier
+	publisher      *publisher.Publisher
+	metrics        *metrics.Recorder
+	journal        *audit.Journal
+	history        *uptime.Store
+	elector        *election.Elector
+	dnsLinks       []*dnsLink
+	fallbackGroups []*fallbackGroup
 }
 
-func NewHelperService() *HelperService {
+func NewHelperService() (*HelperService, error) {
 	fetcherApi := api.NewFetcherApi()
+	agentApi := api.NewAgentApi()
+	webhookApi := api.NewWebhookApi()
 
-	return &HelperService{
-		fetcherApi: fetcherApi,
+	eventNotifier, err := notifier.NewNotifier(config.ApplicationConfig.Notifications, webhookApi)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := audit.NewJournal(config.ApplicationConfig.Audit)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := uptime.NewStore(config.ApplicationConfig.Uptime)
+	if err != nil {
+		return nil, err
+	}
+
+	elector, err := election.NewElector(config.ApplicationConfig.Election)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder, err := metrics.NewRecorder(config.ApplicationConfig.Aws.Metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackGroups, err := newFallbackGroups(config.ApplicationConfig.Application.ISPFallbackUpdater, webhookApi)
+	if err != nil {
+		return nil, err
 	}
+
+	return &HelperService{
+		fetcherApi:     fetcherApi,
+		agentApi:       agentApi,
+		webhookApi:     webhookApi,
+		notifier:       eventNotifier,
+		publisher:      publisher.NewPublisher(config.ApplicationConfig.Aws.Events),
+		metrics:        recorder,
+		journal:        journal,
+		history:        history,
+		elector:        elector,
+		fallbackGroups: fallbackGroups,
+	}, nil
+}
+
+func (service *HelperService) StartMetrics(ctx *context.Context) {
+	service.metrics.Start(ctx, func() (*aws.Config, error) {
+		awsConfig, errw := service.getAWSConfig(ctx)
+		if errw != nil {
+			return nil, errw
+		}
+
+		return awsConfig, nil
+	})
+}
+
+// StartElection must run before the schedulers, so the first checks already know the leadership
+func (service *HelperService) StartElection(ctx *context.Context) {
+	service.elector.Start(ctx, func() (*aws.Config, error) {
+		awsConfig, errw := service.getAWSConfig(ctx)
+		if errw != nil {
+			return nil, errw
+		}
+
+		return awsConfig, nil
+	})
+}
+
+func (service *HelperService) GetElectionStatus() election.Status {
+	return service.elector.Status()
 }
 
 func (service *HelperService) ScheduleDNSUpdater(ctx *context.Context) error {
 	dnsUpdater := config.ApplicationConfig.Application.DNSUpdater
-	hostedZoneIds := dnsUpdater.Record.HostedZoneIds
-	recordTTL := dnsUpdater.Record.TTL
-	recordName := dnsUpdater.Record.Name
-	checkInterval := dnsUpdater.CheckInterval
 
-	go func() {
-		ticker := time.NewTicker(checkInterval)
-		defer ticker.Stop()
+	dnsScheduler, err := scheduler.NewScheduler(DNS_UPDATER_LOOP, dnsUpdater.Schedule)
+	if err != nil {
+		return err
+	}
+
+	var links []*dnsLink
+	for _, linkConfig := range getDNSLinks() {
+		fetcherConfig := linkConfig.PublicIPFetcher
+
+		httpClient, err := api.NewHttpClient(fetcherConfig.Timeout, fetcherConfig.Bind, fetcherConfig.Client)
+		if err != nil {
+			return err
+		}
+
+		links = append(links, newDNSLink(linkConfig, httpClient))
+	}
+
+	service.dnsLinks = links
 
-		for range ticker.C {
-			log.Info(ctx).Msg("Checking DNS...")
+	dnsScheduler.Start(ctx, func() {
+		cycleCtx := log.WithCycle(ctx, DNS_UPDATER_LOOP)
 
-			changed, publicIp, errw := service.isDnsChanged(ctx, recordName)
+		for _, link := range links {
+			linkCtx := log.WithTrace(cycleCtx, log.TRACE_LINK, link.config.Name)
+			service.checkDNSLink(linkCtx, link)
+		}
+	})
+
+	return nil
+}
+
+func getDNSLinks() []config.DNSLink {
+	dnsUpdater := config.ApplicationConfig.Application.DNSUpdater
+	if len(dnsUpdater.Links) > 0 {
+		return dnsUpdater.Links
+	}
+
+	return []config.DNSLink{
+		{
+			Name:            DEFAULT_LINK,
+			PublicIPFetcher: dnsUpdater.PublicIPFetcher,
+			Record:          dnsUpdater.Record,
+		},
+	}
+}
+
+func (service *HelperService) checkDNSLink(ctx *context.Context, link *dnsLink) {
+	record := link.config.Record
+	hostedZoneIds := record.HostedZoneIds
+	recordTTL := record.TTL
+	recordName := record.Name
+
+	log.Info(ctx).Msg(fmt.Sprintf("Checking DNS of link %s...", link.config.Name))
+
+	publicIp, errw := service.getPublicIp(ctx, link)
+	if errw != nil {
+		log.Error(ctx).Msg(fmt.Sprintf("Error on fetching public IP, skipping DNS update: %v", errw.GetMessage()))
+		link.registerFetchError(errw)
+		return
+	}
+
+	link.registerPublicIp(publicIp)
+
+	changed, previousIp, errw := service.isDnsChanged(ctx, recordName, publicIp)
+	if errw != nil {
+		log.Error(ctx).Msg(fmt.Sprintf("Error on checking DNS: %v", errw.GetMessage()))
+	}
+
+	if (changed || errw != nil) && !service.elector.IsLeader() {
+		log.Info(ctx).Msg(fmt.Sprintf("Skipping DNS update of %s, instance is not the leader", recordName))
+
+	} else if changed || errw != nil {
+		ctx = log.WithTrace(ctx, log.TRACE_TRIGGER, TRIGGER_IP_CHANGE)
+
+		awsConfig, errw := service.getAWSConfig(ctx)
+		if errw != nil {
+			log.Error(ctx).Msg(fmt.Sprintf("Error on getting AWS config: %v", errw.GetMessage()))
+			return
+		}
+
+		client := route53.NewFromConfig(*awsConfig)
+		rrType := route53types.RRTypeA
+
+		updated := true
+		for _, hostedZoneId := range hostedZoneIds {
+			errw := service.updateDNS(ctx, client, hostedZoneId, recordName, publicIp, rrType, recordTTL)
 			if errw != nil {
-				log.Error(ctx).Msg(fmt.Sprintf("Error on checking DNS: %v", errw.GetMessage()))
+				log.Error(ctx).Msg(fmt.Sprintf("Error on updating DNS: %v", errw.GetMessage()))
+				service.notifyDnsUpdateFailed(ctx, link, errw)
+				updated = false
 			}
 
-			if changed || errw != nil {
-				awsConfig, errw := service.getAWSConfig(ctx)
-				if errw != nil {
-					log.Error(ctx).Msg(fmt.Sprintf("Error on getting AWS config: %v", errw.GetMessage()))
-					return
-				}
-
-				client := route53.NewFromConfig(*awsConfig)
-				rrType := route53types.RRTypeA
-
-				for _, hostedZoneId := range hostedZoneIds {
-					errw := service.updateDNS(ctx, client, hostedZoneId, recordName, publicIp, rrType, recordTTL)
-					if errw != nil {
-						log.Error(ctx).Msg(fmt.Sprintf("Error on updating DNS: %v", errw.GetMessage()))
-					}
-				}
-
-			} else {
-				log.Info(ctx).Msg(fmt.Sprintf("DNS %s is up to date with public IP %s", recordName, publicIp))
-			}
+			link.registerZoneSync(hostedZoneId, publicIp, errw)
 		}
-	}()
 
-	return nil
+		if updated && changed {
+			service.notifyIpChanged(ctx, link, previousIp, publicIp)
+		}
+
+	} else {
+		log.Info(ctx).Msg(fmt.Sprintf("DNS %s is up to date with public IP %s", recordName, publicIp))
+
+		for _, hostedZoneId := range hostedZoneIds {
+			link.registerZoneSync(hostedZoneId, publicIp, nil)
+		}
+	}
 }
 
 func (service *HelperService) ScheduleISPFallback(ctx *context.Context) error {
-	ispFallbackUpdater := config.ApplicationConfig.Application.ISPFallbackUpdater
-	checkInterval := ispFallbackUpdater.CheckInterval
-	autoscalingGroupName := ispFallbackUpdater.EC2.AutoScalingGroup.Name
-
-	go func() {
-		ticker := time.NewTicker(checkInterval)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			log.Info(ctx).Msg("Checking ISP ports...")
-
-			closed := service.isPortClosed(ctx)
-
-			if (service.ispFallback == nil || *service.ispFallback) && !closed {
-				errw := service.disableISPFallback(ctx)
-				if errw != nil {
-					log.Error(ctx).Msg(fmt.Sprintf("Error on disabling ISP fallback: %v", errw.GetMessage()))
-					service.ispFallback = nil
-				} else {
-					pointer := false
-					service.ispFallback = &pointer
-				}
-
-			} else if (service.ispFallback == nil || !*service.ispFallback) && closed {
-				errw := service.enableISPFallback(ctx)
-				if errw != nil {
-					log.Error(ctx).Msg(fmt.Sprintf("Error on enabling ISP fallback: %v", errw.GetMessage()))
-					service.ispFallback = nil
-				} else {
-					pointer := true
-					service.ispFallback = &pointer
-				}
-			} else if closed {
-				log.Info(ctx).Msg("ISP ports are closed")
-			} else {
-				log.Info(ctx).Msg("ISP ports are open")
-			}
+	for _, group := range service.fallbackGroups {
+		probes, err := prober.NewProbes(group.config.Probes, service.fetcherApi, service.agentApi)
+		if err != nil {
+			return err
+		}
 
-			if service.autoScalingGroupShutdownTime != nil && time.Now().After(*service.autoScalingGroupShutdownTime) {
-				awsConfig, errw := service.getAWSConfig(ctx)
-				if errw != nil {
-					log.Error(ctx).Msg(fmt.Sprintf("Error on getting AWS config: %v", errw.GetMessage()))
-					return
-				}
+		group.probes = probes
 
-				errw = service.updateAutoScallingGroup(ctx, awsConfig, autoscalingGroupName, constants.ZERO)
-				if errw != nil {
-					log.Error(ctx).Msg(fmt.Sprintf("Error on shutting down Auto Scaling Group: %v", errw.GetMessage()))
-					return
-				}
+		group.scheduler, err = scheduler.NewScheduler(ISP_FALLBACK_LOOP+" of group "+group.name(), group.config.Schedule)
+		if err != nil {
+			return err
+		}
+	}
 
-				service.autoScalingGroupShutdownTime = nil
-			}
+	for _, group := range service.fallbackGroups {
+		groupCtx := log.WithTrace(ctx, log.TRACE_GROUP, group.name())
+		group.capacityDue = func(id string) {
+			service.executeScheduledCapacity(groupCtx, group, id)
 		}
-	}()
+
+		service.scheduleFallbackGroup(groupCtx, group)
+	}
 
 	return nil
 }
 
-func (service *HelperService) isDnsChanged(ctx *context.Context, domainName string) (bool, string, *exceptions.WrappedError) {
-	publicIp, erra := service.fetcherApi.GetPublicIp(ctx)
+func (service *HelperService) GetDNSStatus() []DNSLinkStatus {
+	statuses := make([]DNSLinkStatus, 0, len(service.dnsLinks))
+	for _, link := range service.dnsLinks {
+		statuses = append(statuses, link.status())
+	}
+
+	return statuses
+}
+
+func (service *HelperService) GetFallbackStatus() []FallbackGroupStatus {
+	statuses := make([]FallbackGroupStatus, 0, len(service.fallbackGroups))
+	for _, group := range service.fallbackGroups {
+		statuses = append(statuses, group.status())
+	}
+
+	return statuses
+}
+
+func (service *HelperService) scheduleFallbackGroup(ctx *context.Context, group *fallbackGroup) {
+	group.scheduler.Start(ctx, func() {
+		cycleCtx := log.WithCycle(ctx, ISP_FALLBACK_LOOP)
+		service.checkFallbackGroup(cycleCtx, group)
+	})
+}
+
+func (service *HelperService) checkFallbackGroup(ctx *context.Context, group *fallbackGroup) {
+	log.Info(ctx).Msg(fmt.Sprintf("Checking ISP of group %s...", group.name()))
+
+	down := service.isISPDown(ctx, group)
+
+	group.failoverMutex.Lock()
+	defer group.failoverMutex.Unlock()
+
+	leader := service.elector.IsLeader()
+	if forgotten := group.observeLeadership(leader); forgotten != nil {
+		log.Info(ctx).Msg(fmt.Sprintf("Instance became the leader, fallback %t of group %s is applied again once the thresholds are met", *forgotten, group.name()))
+	}
+
+	shouldDisable := group.shouldDisable()
+	shouldEnable := !shouldDisable && group.shouldEnable()
+	quietWindow := scheduler.ActiveQuietWindow(group.quietWindows, time.Now())
+
+	if !shouldDisable && !shouldEnable {
+		group.clearSuppressed()
+	}
+
+	if !leader && (shouldDisable || shouldEnable) {
+		log.Info(ctx).Msg(fmt.Sprintf("Skipping fallback change of group %s, instance is not the leader", group.name()))
+	} else if quietWindow != nil && (shouldDisable || shouldEnable) {
+		service.suppressISPFallback(ctx, group, quietWindow, shouldEnable)
+	} else if shouldDisable {
+		service.switchISPFallback(ctx, group, false, TRIGGER_FALLBACK_DISABLE)
+	} else if shouldEnable {
+		service.switchISPFallback(ctx, group, true, TRIGGER_FALLBACK_ENABLE)
+	} else if down {
+		log.Info(ctx).Msg(fmt.Sprintf("ISP of group %s is down", group.name()))
+	} else {
+		log.Info(ctx).Msg(fmt.Sprintf("ISP of group %s is up", group.name()))
+	}
+
+	groupStatus := group.status()
+	service.metrics.RecordGroup(group.name(), groupStatus.Down, groupStatus.Fallback != nil && *groupStatus.Fallback)
+}
+
+// ChangeISPFallback switches a group on demand, the next checks still apply the thresholds and may revert it
+func (service *HelperService) ChangeISPFallback(ctx *context.Context, groupName string, fallback bool) *exceptions.WrappedError {
+	if !service.elector.IsLeader() {
+		return &exceptions.WrappedError{
+			Code: exceptions.PRECONDITION_FAILED,
+			Err:  fmt.Errorf("Instance is not the leader, current leader is %s", service.elector.Leader()),
+		}
+	}
+
+	for _, group := range service.fallbackGroups {
+		if group.name() != groupName {
+			continue
+		}
+
+		group.failoverMutex.Lock()
+		defer group.failoverMutex.Unlock()
+
+		groupCtx := log.WithTrace(ctx, log.TRACE_GROUP, group.name())
+		return service.switchISPFallback(groupCtx, group, fallback, TRIGGER_MANUAL)
+	}
+
+	return &exceptions.WrappedError{
+		Code: exceptions.HTTP_NOT_FOUND,
+		Err:  errors.New("Fallback group not found: " + groupName),
+	}
+}
+
+// suppressISPFallback skips a fallback change during a quiet window, notify windows emit one event per pending change
+func (service *HelperService) suppressISPFallback(ctx *context.Context, group *fallbackGroup, quietWindow *scheduler.QuietWindow, fallback bool) {
+	log.Warn(ctx).Msg(fmt.Sprintf("Fallback change of group %s to %t suppressed by quiet window %s", group.name(), fallback, quietWindow.Name()))
+
+	if group.markSuppressed(fallback) && quietWindow.Mode() == quiet.NOTIFY {
+		service.notifyFallbackSuppressed(ctx, group, quietWindow, fallback)
+	}
+}
+
+func (service *HelperService) switchISPFallback(ctx *context.Context, group *fallbackGroup, fallback bool, trigger string) *exceptions.WrappedError {
+	failoverCtx := log.WithTrace(ctx, log.TRACE_FAILOVER_ID, log.NewTraceId())
+	failoverCtx = log.WithTrace(failoverCtx, log.TRACE_TRIGGER, trigger)
+
+	var errw *exceptions.WrappedError
+	if fallback {
+		errw = service.enableISPFallback(failoverCtx, group)
+		if errw != nil {
+			log.Error(failoverCtx).Msg(fmt.Sprintf("Error on enabling ISP fallback: %v", errw.GetMessage()))
+		}
+	} else {
+		errw = service.disableISPFallback(failoverCtx, group)
+		if errw != nil {
+			log.Error(failoverCtx).Msg(fmt.Sprintf("Error on disabling ISP fallback: %v", errw.GetMessage()))
+		}
+	}
+
+	if errw != nil {
+		group.setFallback(nil)
+	} else {
+		group.setFallback(&fallback)
+	}
+
+	return errw
+}
+
+func (service *HelperService) getPublicIp(ctx *context.Context, link *dnsLink) (string, *exceptions.WrappedError) {
+	response, erra := service.fetcherApi.GetPublicIp(ctx, link.httpClient, link.config.PublicIPFetcher)
 	if erra != nil {
-		return false, publicIp, erra.ToWrappedError(ctx)
+		return "", erra.ToWrappedError(ctx)
 	}
 
+	publicIp := strings.TrimSpace(response)
+	ip := net.ParseIP(publicIp)
+	if ip == nil || ip.To4() == nil {
+		return "", &exceptions.WrappedError{
+			Err:  errors.New("Invalid public IPv4 address: " + publicIp),
+			Code: exceptions.INVALID_RESPONSE,
+		}
+	}
+
+	return ip.String(), nil
+}
+
+func (service *HelperService) isDnsChanged(ctx *context.Context, domainName string, publicIp string) (bool, string, *exceptions.WrappedError) {
 	resolvedIp, errw := service.resolveDNS(ctx, domainName)
 	if errw != nil {
-		return false, publicIp, errw
+		return false, "", errw
 	}
 
 	if publicIp != resolvedIp {
 		log.Info(ctx).Msg(fmt.Sprintf("Public IP changed from %s to %s", resolvedIp, publicIp))
-		return true, publicIp, nil
+		return true, resolvedIp, nil
 	}
 
-	return false, publicIp, nil
+	return false, resolvedIp, nil
 }
 
 func (service *HelperService) resolveDNS(ctx *context.Context, domainName string) (string, *exceptions.WrappedError) {
@@ -164,7 +434,8 @@ func (service *HelperService) resolveDNS(ctx *context.Context, domainName string
 	ips, err := net.LookupIP(domainName)
 	if err != nil {
 		return "", &exceptions.WrappedError{
-			Error: err,
+			Err:  err,
+			Code: exceptions.NETWORK,
 		}
 	}
 
@@ -197,13 +468,18 @@ func (service *HelperService) updateDNS(ctx *context.Context, client *route53.Cl
 		ChangeBatch:  changeBatch,
 	}
 
+	oldValue := currentRecordValue(ctx, client, hostedZoneId, recordName, rrtype)
+	resource := fmt.Sprintf("%s/%s/%s", hostedZoneId, recordName, rrtype)
+
 	_, err := client.ChangeResourceRecordSets(*ctx, input)
 	if err != nil {
-		return &exceptions.WrappedError{
-			Error: err,
-		}
+		errw := wrapAwsError(ctx, err)
+		service.audit(ctx, action.ROUTE53_RECORD, resource, oldValue, value, errw)
+
+		return errw
 	}
 
+	service.audit(ctx, action.ROUTE53_RECORD, resource, oldValue, value, nil)
 	log.Info(ctx).Msg(fmt.Sprintf("DNS record for %s updated with value: %s", recordName, value))
 	return nil
 }
@@ -214,96 +490,108 @@ func (service *HelperService) getAWSConfig(ctx *context.Context) (*aws.Config, *
 	secretKey := awsConfig.Credentials.SecretKey
 	region := awsConfig.Region
 
-	cfg, err := awsconfig.LoadDefaultConfig(*ctx,
+	proxyFunc, err := network.NewProxyFunc(awsConfig.Proxy)
+	if err != nil {
+		return nil, &exceptions.WrappedError{
+			Err:  err,
+			Code: exceptions.CONFIG,
+		}
+	}
+
+	httpClient := awshttp.NewBuildableClient().WithTransportOptions(func(transport *http.Transport) {
+		transport.Proxy = proxyFunc
+	})
+
+	retryer, err := newAwsRetryer(awsConfig.Retry)
+	if err != nil {
+		return nil, &exceptions.WrappedError{
+			Err:  err,
+			Code: exceptions.CONFIG,
+		}
+	}
+
+	options := []func(*awsconfig.LoadOptions) error{
 		awsconfig.WithRegion(region),
 		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
-	)
+		awsconfig.WithHTTPClient(httpClient),
+		awsconfig.WithLogger(log.NewSdkLogger(ctx)),
+		awsconfig.WithRetryer(retryer),
+	}
+
+	logMode := aws.LogRetries
+	if awsConfig.LogRequests && log.IsLevelEnabled(log.DEBUG) {
+		logMode |= aws.LogRequestWithBody | aws.LogResponseWithBody
+	}
+
+	options = append(options, awsconfig.WithClientLogMode(logMode))
+
+	cfg, err := awsconfig.LoadDefaultConfig(*ctx, options...)
 	if err != nil {
 		return nil, &exceptions.WrappedError{
-			Error: err,
+			Err:  err,
+			Code: exceptions.CONFIG,
 		}
 	}
 
 	return &cfg, nil
 }
 
-func (service *HelperService) isPortClosed(ctx *context.Context) bool {
-	erra := service.fetcherApi.Fetch(ctx)
-	return erra != nil && erra.Status == constants.ZERO
+func (service *HelperService) isISPDown(ctx *context.Context, group *fallbackGroup) bool {
+	var probeResults []prober.Result
+
+	for _, probe := range group.probes {
+		result := probe.Check(ctx)
+		probeResults = append(probeResults, result)
+		service.metrics.RecordProbe(group.name(), result.Probe, result.Success, result.Latency)
+
+		if result.Success {
+			log.Info(ctx).PutTraceMap("class", result.Class).Msg(fmt.Sprintf("Probe %s succeeded in %s: %s", result.Probe, result.Latency, result.Message))
+		} else {
+			log.Warn(ctx).PutTraceMap("class", result.Class).Msg(fmt.Sprintf("Probe %s failed with %s in %s: %s", result.Probe, result.Class, result.Latency, result.Message))
+		}
+	}
+
+	down := prober.IsDown(probeResults, group.config.DownWhen, group.config.DownClasses)
+	group.registerCheck(down, probeResults)
+	service.recordUptime(ctx, group, down, probeResults)
+
+	return down
 }
 
-func (service *HelperService) enableISPFallback(ctx *context.Context) *exceptions.WrappedError {
-	log.Info(ctx).Msg("Enabling ISP fallback")
+func (service *HelperService) enableISPFallback(ctx *context.Context, group *fallbackGroup) *exceptions.WrappedError {
+	log.Info(ctx).Msg(fmt.Sprintf("Enabling ISP fallback of group %s", group.name()))
 
-	errw := service.changeISPFallback(ctx, true)
+	errw := service.changeISPFallback(ctx, group, true)
 	if errw == nil {
-		log.Info(ctx).Msg("ISP fallback enabled")
+		log.Info(ctx).Msg(fmt.Sprintf("ISP fallback of group %s enabled", group.name()))
 	}
 
 	return errw
 }
 
-func (service *HelperService) disableISPFallback(ctx *context.Context) *exceptions.WrappedError {
-	log.Info(ctx).Msg("Disabling ISP fallback")
+func (service *HelperService) disableISPFallback(ctx *context.Context, group *fallbackGroup) *exceptions.WrappedError {
+	log.Info(ctx).Msg(fmt.Sprintf("Disabling ISP fallback of group %s", group.name()))
 
-	errw := service.changeISPFallback(ctx, false)
+	errw := service.changeISPFallback(ctx, group, false)
 	if errw == nil {
-		log.Info(ctx).Msg("ISP fallback disabled")
+		log.Info(ctx).Msg(fmt.Sprintf("ISP fallback of group %s disabled", group.name()))
 	}
 
 	return errw
 }
 
-func (service *HelperService) changeISPFallback(ctx *context.Context, fallback bool) *exceptions.WrappedError {
-	ispFallbackUpdater := config.ApplicationConfig.Application.ISPFallbackUpdater
-	hostedZoneIds := ispFallbackUpdater.Record.HostedZoneIds
-	recordName := ispFallbackUpdater.Record.Name
-	recordValue := ispFallbackUpdater.Record.Value.Normal
-	recordTTL := ispFallbackUpdater.Record.TTL
-	rrType := route53types.RRTypeCname
-	autoscalingGroupName := ispFallbackUpdater.EC2.AutoScalingGroup.Name
-	autoScalingGroupShutdownTime := ispFallbackUpdater.EC2.AutoScalingGroup.ShutdownTime
-	distributionId := ispFallbackUpdater.Cloudfront.DistributionId
-	distributionOrigin := ispFallbackUpdater.Cloudfront.Origin.Normal
-
-	awsConfig, errw := service.getAWSConfig(ctx)
-	if errw != nil {
-		return errw
-	}
-
+func (service *HelperService) changeISPFallback(ctx *context.Context, group *fallbackGroup, fallback bool) *exceptions.WrappedError {
+	actions := group.config.Actions.Disable
 	if fallback {
-		recordValue = ispFallbackUpdater.Record.Value.Fallback
-		distributionOrigin = ispFallbackUpdater.Cloudfront.Origin.Fallback
-
-		errw = service.updateAutoScallingGroup(ctx, awsConfig, autoscalingGroupName, constants.ONE)
-		if errw != nil {
-			return errw
-		}
-
-		errw = service.updateCloudfrontDistribution(ctx, awsConfig, distributionId, distributionOrigin)
-		if errw != nil {
-			return errw
-		}
-
-	} else {
-		futureTime := time.Now().Add(autoScalingGroupShutdownTime)
-		service.autoScalingGroupShutdownTime = &futureTime
-
-		log.Info(ctx).Msg(fmt.Sprintf("Shutting down auto scaling group %s at %s", autoscalingGroupName, futureTime))
-
-		errw = service.updateCloudfrontDistribution(ctx, awsConfig, distributionId, distributionOrigin)
-		if errw != nil {
-			return errw
-		}
+		actions = group.config.Actions.Enable
 	}
 
-	client := route53.NewFromConfig(*awsConfig)
-	for _, hostedZoneId := range hostedZoneIds {
-		errw := service.updateDNS(ctx, client, hostedZoneId, recordName, recordValue, rrType, recordTTL)
-		return errw
-	}
+	service.cancelOppositeCapacities(ctx, group, fallback)
 
-	return nil
+	errw := service.executeActions(ctx, group, actions, fallback)
+	service.notifyFallback(ctx, group, fallback, errw)
+
+	return errw
 }
 
 func (service *HelperService) updateAutoScallingGroup(ctx *context.Context, awsConfig *aws.Config, autoscalingGroupName string, desired int32) *exceptions.WrappedError {
@@ -317,13 +605,19 @@ func (service *HelperService) updateAutoScallingGroup(ctx *context.Context, awsC
 		DesiredCapacity:      aws.Int32(desired),
 	}
 
+	oldValue := currentCapacity(ctx, client, autoscalingGroupName)
+	newValue := formatCapacity(desired, desired, desired)
+
 	_, err := client.UpdateAutoScalingGroup(*ctx, input)
 	if err != nil {
-		return &exceptions.WrappedError{
-			Error: err,
-		}
+		errw := wrapAwsError(ctx, err)
+		service.audit(ctx, action.ASG_CAPACITY, autoscalingGroupName, oldValue, newValue, errw)
+
+		return errw
 	}
 
+	service.audit(ctx, action.ASG_CAPACITY, autoscalingGroupName, oldValue, newValue, nil)
+
 	log.Info(ctx).Msg(fmt.Sprintf("Updated auto scaling group %s to desired capacity %d", autoscalingGroupName, desired))
 
 	return nil
@@ -338,12 +632,11 @@ func (service *HelperService) updateCloudfrontDistribution(ctx *context.Context,
 
 	getDistributionConfigOutput, err := client.GetDistributionConfig(*ctx, getInput)
 	if err != nil {
-		return &exceptions.WrappedError{
-			Error: err,
-		}
+		return wrapAwsError(ctx, err)
 	}
 
 	distributionConfig := getDistributionConfigOutput.DistributionConfig
+	oldOrigin := aws.ToString(distributionConfig.DefaultCacheBehavior.TargetOriginId)
 	distributionConfig.DefaultCacheBehavior.TargetOriginId = aws.String(origin)
 
 	input := &cloudfront.UpdateDistributionInput{
@@ -354,10 +647,12 @@ func (service *HelperService) updateCloudfrontDistribution(ctx *context.Context,
 
 	_, err = client.UpdateDistribution(*ctx, input)
 	if err != nil {
-		return &exceptions.WrappedError{
-			Error: err,
-		}
+		errw := wrapAwsError(ctx, err)
+		service.audit(ctx, action.CLOUDFRONT_ORIGIN, distributionId, oldOrigin, origin, errw)
+
+		return errw
 	}
 
+	service.audit(ctx, action.CLOUDFRONT_ORIGIN, distributionId, oldOrigin, origin, nil)
 	return nil
 }
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SCHEDULED_CAPACITY_RETRY_DELAY postpones a due job whose fallback state is unknown or whose update failed with a retryable error
+const SCHEDULED_CAPACITY_RETRY_DELAY = time.Minute
+
+// scheduledCapacity is a delayed capacity change of an auto scaling group, it belongs to the fallback
+// state whose actions created it and only runs while the group is still in that state.
+// Each job has its own timer, so it runs on time whatever the check schedule of the group is
+type scheduledCapacity struct {
+	id               string
+	autoScalingGroup string
+	capacity         int32
+	fallback         bool
+	scheduledAt      time.Time
+	executeAt        time.Time
+	extensions       int
+	timer            *time.Timer
+}
+
+type ScheduledCapacityStatus struct {
+	Id               string    `json:"id"`
+	Group            string    `json:"group"`
+	AutoScalingGroup string    `json:"autoScalingGroup"`
+	Capacity         int32     `json:"capacity"`
+	Fallback         bool      `json:"fallback"`
+	ScheduledAt      time.Time `json:"scheduledAt"`
+	ExecuteAt        time.Time `json:"executeAt"`
+	Extensions       int       `json:"extensions"`
+}
+
+// scheduleCapacity keeps one job per auto scaling group, scheduling again for the same fallback state
+// extends the pending job, so repeated recoveries postpone the change instead of running the older one
+func (group *fallbackGroup) scheduleCapacity(scheduled scheduledCapacity) (scheduledCapacity, bool) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	existing, exists := group.scheduledCapacities[scheduled.autoScalingGroup]
+	if exists && existing.fallback == scheduled.fallback {
+		existing.capacity = scheduled.capacity
+		if scheduled.executeAt.After(existing.executeAt) {
+			existing.executeAt = scheduled.executeAt
+		}
+
+		existing.extensions++
+		group.armCapacity(&existing, time.Until(existing.executeAt))
+		group.scheduledCapacities[existing.autoScalingGroup] = existing
+
+		return existing, true
+	}
+
+	if exists {
+		existing.stop()
+	}
+
+	group.armCapacity(&scheduled, time.Until(scheduled.executeAt))
+	group.scheduledCapacities[scheduled.autoScalingGroup] = scheduled
+	return scheduled, false
+}
+
+// armCapacity (re)starts the timer of a job, it expects the caller to hold the group lock
+func (group *fallbackGroup) armCapacity(scheduled *scheduledCapacity, delay time.Duration) {
+	scheduled.stop()
+
+	id := scheduled.id
+	scheduled.timer = time.AfterFunc(delay, func() {
+		if group.capacityDue != nil {
+			group.capacityDue(id)
+		}
+	})
+}
+
+// retryCapacity runs a due job again after the delay, unless it was cancelled or rescheduled meanwhile
+func (group *fallbackGroup) retryCapacity(id string, delay time.Duration) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	for autoscalingGroupName, scheduled := range group.scheduledCapacities {
+		if scheduled.id == id {
+			group.armCapacity(&scheduled, delay)
+			group.scheduledCapacities[autoscalingGroupName] = scheduled
+		}
+	}
+}
+
+func (scheduled scheduledCapacity) stop() {
+	if scheduled.timer != nil {
+		scheduled.timer.Stop()
+	}
+}
+
+func (group *fallbackGroup) removeScheduledCapacity(autoscalingGroupName string) (scheduledCapacity, bool) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	scheduled, exists := group.scheduledCapacities[autoscalingGroupName]
+	delete(group.scheduledCapacities, autoscalingGroupName)
+	scheduled.stop()
+
+	return scheduled, exists
+}
+
+// cancelScheduledCapacities removes the jobs created by the other fallback state
+func (group *fallbackGroup) cancelScheduledCapacities(fallback bool) []scheduledCapacity {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	var cancelled []scheduledCapacity
+	for autoscalingGroupName, scheduled := range group.scheduledCapacities {
+		if scheduled.fallback != fallback {
+			scheduled.stop()
+			cancelled = append(cancelled, scheduled)
+			delete(group.scheduledCapacities, autoscalingGroupName)
+		}
+	}
+
+	return cancelled
+}
+
+func (group *fallbackGroup) findScheduledCapacity(id string) (scheduledCapacity, bool) {
+	group.mutex.RLock()
+	defer group.mutex.RUnlock()
+
+	for _, scheduled := range group.scheduledCapacities {
+		if scheduled.id == id {
+			return scheduled, true
+		}
+	}
+
+	return scheduledCapacity{}, false
+}
+
+func (group *fallbackGroup) rescheduleCapacity(id string, executeAt time.Time) (scheduledCapacity, bool) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+
+	for autoscalingGroupName, scheduled := range group.scheduledCapacities {
+		if scheduled.id == id {
+			scheduled.executeAt = executeAt
+			scheduled.extensions++
+			group.armCapacity(&scheduled, time.Until(executeAt))
+			group.scheduledCapacities[autoscalingGroupName] = scheduled
+
+			return scheduled, true
+		}
+	}
+
+	return scheduledCapacity{}, false
+}
+
+// scheduledCapacityStatuses expects the caller to hold the group lock
+func (group *fallbackGroup) scheduledCapacityStatuses() []ScheduledCapacityStatus {
+	statuses := make([]ScheduledCapacityStatus, 0, len(group.scheduledCapacities))
+	for _, scheduled := range group.scheduledCapacities {
+		statuses = append(statuses, scheduled.status(group.name()))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ExecuteAt.Before(statuses[j].ExecuteAt)
+	})
+
+	return statuses
+}
+
+func (scheduled scheduledCapacity) status(groupName string) ScheduledCapacityStatus {
+	return ScheduledCapacityStatus{
+		Id:               scheduled.id,
+		Group:            groupName,
+		AutoScalingGroup: scheduled.autoScalingGroup,
+		Capacity:         scheduled.capacity,
+		Fallback:         scheduled.fallback,
+		ScheduledAt:      scheduled.scheduledAt,
+		ExecuteAt:        scheduled.executeAt,
+		Extensions:       scheduled.extensions,
+	}
+}
+
+func (service *HelperService) GetScheduledCapacities() []ScheduledCapacityStatus {
+	statuses := []ScheduledCapacityStatus{}
+	for _, group := range service.fallbackGroups {
+		statuses = append(statuses, group.status().ScheduledCapacities...)
+	}
+
+	return statuses
+}
+
+func (service *HelperService) CancelScheduledCapacity(ctx *context.Context, groupName string, id string) (*ScheduledCapacityStatus, *exceptions.WrappedError) {
+	group, scheduled, errw := service.findScheduledCapacity(groupName, id)
+	if errw != nil {
+		return nil, errw
+	}
+
+	group.failoverMutex.Lock()
+	defer group.failoverMutex.Unlock()
+
+	scheduled, removed := group.removeScheduledCapacity(scheduled.autoScalingGroup)
+	if !removed || scheduled.id != id {
+		return nil, scheduledCapacityNotFound(id)
+	}
+
+	groupCtx := log.WithTrace(ctx, log.TRACE_GROUP, group.name())
+	log.Info(groupCtx).Msg(fmt.Sprintf("Scheduled capacity %s of auto scaling group %s cancelled", id, scheduled.autoScalingGroup))
+	service.notifyCapacityCancelled(groupCtx, group, scheduled, TRIGGER_MANUAL)
+
+	status := scheduled.status(group.name())
+	return &status, nil
+}
+
+// RescheduleCapacity only moves a job to a future time, running it right away is not a reschedule
+func (service *HelperService) RescheduleCapacity(ctx *context.Context, groupName string, id string, executeAt time.Time) (*ScheduledCapacityStatus, *exceptions.WrappedError) {
+	if !executeAt.After(time.Now()) {
+		return nil, &exceptions.WrappedError{
+			Code: exceptions.HTTP_CLIENT_ERROR,
+			Err:  fmt.Errorf("Scheduled capacity can only be moved to the future, %s is not", executeAt.Format(time.RFC3339)),
+		}
+	}
+
+	group, _, errw := service.findScheduledCapacity(groupName, id)
+	if errw != nil {
+		return nil, errw
+	}
+
+	group.failoverMutex.Lock()
+	defer group.failoverMutex.Unlock()
+
+	scheduled, exists := group.rescheduleCapacity(id, executeAt)
+	if !exists {
+		return nil, scheduledCapacityNotFound(id)
+	}
+
+	groupCtx := log.WithTrace(ctx, log.TRACE_GROUP, group.name())
+	log.Info(groupCtx).Msg(fmt.Sprintf("Scheduled capacity %s of auto scaling group %s rescheduled to %s", id, scheduled.autoScalingGroup, executeAt.Format(time.RFC3339)))
+	service.notifyCapacityScheduled(groupCtx, group, scheduled)
+
+	status := scheduled.status(group.name())
+	return &status, nil
+}
+
+func (service *HelperService) findScheduledCapacity(groupName string, id string) (*fallbackGroup, scheduledCapacity, *exceptions.WrappedError) {
+	for _, group := range service.fallbackGroups {
+		if group.name() != groupName {
+			continue
+		}
+
+		scheduled, exists := group.findScheduledCapacity(id)
+		if !exists {
+			return nil, scheduledCapacity{}, scheduledCapacityNotFound(id)
+		}
+
+		return group, scheduled, nil
+	}
+
+	return nil, scheduledCapacity{}, &exceptions.WrappedError{
+		Code: exceptions.HTTP_NOT_FOUND,
+		Err:  errors.New("Fallback group not found: " + groupName),
+	}
+}
+
+func scheduledCapacityNotFound(id string) *exceptions.WrappedError {
+	return &exceptions.WrappedError{
+		Code: exceptions.HTTP_NOT_FOUND,
+		Err:  errors.New("Scheduled capacity not found: " + id),
+	}
+}
+
+// cancelOppositeCapacities runs before the actions of a fallback change, so a shutdown scheduled
+// by a recovery never fires after the fallback was enabled again
+func (service *HelperService) cancelOppositeCapacities(ctx *context.Context, group *fallbackGroup, fallback bool) {
+	for _, scheduled := range group.cancelScheduledCapacities(fallback) {
+		log.Info(ctx).Msg(fmt.Sprintf("Cancelling scheduled capacity %d of auto scaling group %s, fallback changed to %t", scheduled.capacity, scheduled.autoScalingGroup, fallback))
+		service.notifyCapacityCancelled(ctx, group, scheduled, traceString(ctx, log.TRACE_TRIGGER))
+	}
+}
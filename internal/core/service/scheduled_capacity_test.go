@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/lease"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/election"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/metrics"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/notifier"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/publisher"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestGroup(t *testing.T) *fallbackGroup {
+	group, err := newFallbackGroup(config.FallbackGroup{Name: "home"}, api.NewWebhookApi())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		for _, scheduled := range group.scheduledCapacities {
+			scheduled.stop()
+		}
+	})
+
+	return group
+}
+
+// newTestService builds a service without AWS access, a follower holds an election it never started
+func newTestService(t *testing.T, leader bool, group *fallbackGroup) *HelperService {
+	eventNotifier, err := notifier.NewNotifier(config.Notifications{}, api.NewWebhookApi())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	electionConfig := config.Election{Id: "test"}
+	if !leader {
+		electionConfig.Enabled = true
+		electionConfig.Backend = lease.FILE
+		electionConfig.File.Path = filepath.Join(t.TempDir(), "leader.json")
+	}
+
+	elector, err := election.NewElector(electionConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder, err := metrics.NewRecorder(config.Metrics{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &HelperService{
+		notifier:       eventNotifier,
+		publisher:      publisher.NewPublisher(config.AwsEvents{}),
+		metrics:        recorder,
+		elector:        elector,
+		fallbackGroups: []*fallbackGroup{group},
+	}
+}
+
+// dueRecorder collects the jobs whose timers fired
+type dueRecorder struct {
+	ids   []string
+	mutex sync.Mutex
+}
+
+func (recorder *dueRecorder) due(id string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.ids = append(recorder.ids, id)
+}
+
+func (recorder *dueRecorder) fired() []string {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return append([]string{}, recorder.ids...)
+}
+
+func TestScheduleCapacityExtendsSameFallbackState(t *testing.T) {
+	group := newTestGroup(t)
+	now := time.Now()
+
+	first, extended := group.scheduleCapacity(scheduledCapacity{id: "first", autoScalingGroup: "asg", capacity: 0, executeAt: now.Add(time.Hour)})
+	if extended {
+		t.Fatal("new job reported as extended")
+	}
+
+	later, extended := group.scheduleCapacity(scheduledCapacity{id: "second", autoScalingGroup: "asg", capacity: 1, executeAt: now.Add(2 * time.Hour)})
+	if !extended || later.id != first.id || !later.executeAt.Equal(now.Add(2*time.Hour)) || later.capacity != 1 || later.extensions != 1 {
+		t.Errorf("job not extended: %+v", later)
+	}
+
+	earlier, _ := group.scheduleCapacity(scheduledCapacity{id: "third", autoScalingGroup: "asg", executeAt: now.Add(time.Minute)})
+	if earlier.id != first.id || !earlier.executeAt.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("extension moved the job earlier: %+v", earlier)
+	}
+
+	replaced, extended := group.scheduleCapacity(scheduledCapacity{id: "opposite", autoScalingGroup: "asg", fallback: true, executeAt: now.Add(time.Minute)})
+	if extended || replaced.id != "opposite" || len(group.scheduledCapacities) != 1 {
+		t.Errorf("job of the other fallback state not replaced: %+v", replaced)
+	}
+}
+
+func TestCancelScheduledCapacitiesOfOtherFallbackState(t *testing.T) {
+	group := newTestGroup(t)
+	now := time.Now()
+
+	group.scheduleCapacity(scheduledCapacity{id: "shutdown", autoScalingGroup: "fallback-asg", fallback: false, executeAt: now.Add(time.Hour)})
+	group.scheduleCapacity(scheduledCapacity{id: "warmup", autoScalingGroup: "standby-asg", fallback: true, executeAt: now.Add(time.Hour)})
+
+	// the fallback was enabled again, the shutdown scheduled by the recovery must not run
+	cancelled := group.cancelScheduledCapacities(true)
+	if len(cancelled) != 1 || cancelled[0].id != "shutdown" {
+		t.Errorf("unexpected cancelled jobs %+v", cancelled)
+	}
+
+	if _, exists := group.findScheduledCapacity("warmup"); !exists {
+		t.Error("job of the current fallback state cancelled")
+	}
+}
+
+func TestScheduledCapacityTimers(t *testing.T) {
+	group := newTestGroup(t)
+	recorder := &dueRecorder{}
+	group.capacityDue = recorder.due
+
+	now := time.Now()
+	group.scheduleCapacity(scheduledCapacity{id: "extended", autoScalingGroup: "extended-asg", executeAt: now.Add(20 * time.Millisecond)})
+	group.scheduleCapacity(scheduledCapacity{id: "extension", autoScalingGroup: "extended-asg", executeAt: now.Add(300 * time.Millisecond)})
+	group.scheduleCapacity(scheduledCapacity{id: "cancelled", autoScalingGroup: "cancelled-asg", executeAt: now.Add(20 * time.Millisecond)})
+	group.scheduleCapacity(scheduledCapacity{id: "due", autoScalingGroup: "due-asg", executeAt: now.Add(20 * time.Millisecond)})
+	group.removeScheduledCapacity("cancelled-asg")
+
+	time.Sleep(150 * time.Millisecond)
+	if fired := recorder.fired(); len(fired) != 1 || fired[0] != "due" {
+		t.Fatalf("fired %v before the extension, expected only due", fired)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if fired := recorder.fired(); len(fired) != 2 || fired[1] != "extended" {
+		t.Errorf("fired %v, expected the extended job once", fired)
+	}
+}
+
+func TestRescheduleCapacity(t *testing.T) {
+	tests := []struct {
+		name      string
+		group     string
+		id        string
+		executeAt time.Time
+		code      exceptions.Code
+	}{
+		{"future", "home", "job", time.Now().Add(2 * time.Hour), ""},
+		{"past", "home", "job", time.Now().Add(-time.Minute), exceptions.HTTP_CLIENT_ERROR},
+		{"zero", "home", "job", time.Time{}, exceptions.HTTP_CLIENT_ERROR},
+		{"now", "home", "job", time.Now(), exceptions.HTTP_CLIENT_ERROR},
+		{"unknown job", "home", "other", time.Now().Add(time.Hour), exceptions.HTTP_NOT_FOUND},
+		{"unknown group", "office", "job", time.Now().Add(time.Hour), exceptions.HTTP_NOT_FOUND},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			group := newTestGroup(t)
+			recorder := &dueRecorder{}
+			group.capacityDue = recorder.due
+
+			executeAt := time.Now().Add(time.Hour)
+			group.scheduleCapacity(scheduledCapacity{id: "job", autoScalingGroup: "asg", executeAt: executeAt})
+
+			service := newTestService(t, true, group)
+			ctx := context.Background()
+
+			status, errw := service.RescheduleCapacity(&ctx, test.group, test.id, test.executeAt)
+			if errw != nil || test.code != "" {
+				if errw == nil || errw.GetCode() != test.code {
+					t.Fatalf("error %v, expected code %s", errw, test.code)
+				}
+
+				if scheduled, _ := group.findScheduledCapacity("job"); !scheduled.executeAt.Equal(executeAt) {
+					t.Errorf("rejected reschedule moved the job to %s", scheduled.executeAt)
+				}
+			} else if !status.ExecuteAt.Equal(test.executeAt) || status.Extensions != 1 {
+				t.Errorf("unexpected status %+v", status)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			if fired := recorder.fired(); len(fired) > 0 {
+				t.Errorf("reschedule ran the job right away: %v", fired)
+			}
+		})
+	}
+}
+
+func TestExecuteScheduledCapacityChecksState(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		name     string
+		leader   bool
+		state    *bool
+		due      bool
+		expected bool
+	}{
+		{"not due yet", true, &disabled, false, true},
+		{"unknown state postponed", true, nil, true, true},
+		{"fallback changed", true, &enabled, true, false},
+		{"not the leader", false, &disabled, true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			group := newTestGroup(t)
+			group.setFallback(test.state)
+
+			executeAt := time.Now().Add(time.Hour)
+			if test.due {
+				executeAt = time.Now().Add(-time.Second)
+			}
+
+			group.scheduledCapacities["asg"] = scheduledCapacity{id: "job", autoScalingGroup: "asg", fallback: false, executeAt: executeAt}
+
+			service := newTestService(t, test.leader, group)
+			ctx := context.Background()
+			service.executeScheduledCapacity(&ctx, group, "job")
+
+			scheduled, exists := group.findScheduledCapacity("job")
+			if exists != test.expected {
+				t.Fatalf("job kept = %t, expected %t", exists, test.expected)
+			}
+
+			if exists && test.due && scheduled.timer == nil {
+				t.Error("postponed job has no retry timer")
+			}
+		})
+	}
+}
+
+func TestObserveLeadershipForgetsFallbackState(t *testing.T) {
+	group := newTestGroup(t)
+	group.consecutiveSuccesses = group.recoveryThreshold()
+
+	disabled := false
+	group.setFallback(&disabled)
+
+	if forgotten := group.observeLeadership(false); forgotten != nil || group.ispFallback == nil {
+		t.Fatal("follower forgot the fallback state")
+	}
+
+	if group.shouldDisable() {
+		t.Fatal("known state applied again")
+	}
+
+	// taking over the leadership, the recovery actions run again and schedule the pending capacities
+	if forgotten := group.observeLeadership(true); forgotten == nil || *forgotten != disabled || group.ispFallback != nil {
+		t.Fatalf("fallback state kept when becoming the leader, forgotten %v", forgotten)
+	}
+
+	if !group.shouldDisable() {
+		t.Error("new leader does not apply the recovery again")
+	}
+
+	group.setFallback(&disabled)
+	if forgotten := group.observeLeadership(true); forgotten != nil || group.ispFallback == nil {
+		t.Error("state forgotten while staying the leader")
+	}
+}
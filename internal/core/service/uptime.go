@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/prober"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/uptime"
+	"time"
+)
+
+func (service *HelperService) recordUptime(ctx *context.Context, group *fallbackGroup, down bool, probeResults []prober.Result) {
+	now := time.Now()
+
+	samples := []uptime.Sample{{
+		Time:  now,
+		Group: group.name(),
+		Up:    !down,
+	}}
+
+	for _, result := range probeResults {
+		samples = append(samples, uptime.Sample{
+			Time:    now,
+			Group:   group.name(),
+			Probe:   result.Probe,
+			Up:      result.Success,
+			Latency: result.Latency,
+			Class:   string(result.Class),
+		})
+	}
+
+	err := service.history.Record(samples...)
+	if err != nil {
+		log.Error(ctx).Msg("Error on writing uptime history: " + err.Error())
+	}
+}
+
+func (service *HelperService) GetUptimeReports(group string, from time.Time, to time.Time) ([]uptime.Report, error) {
+	return uptime.Query(uptime.Path(config.ApplicationConfig.Uptime), group, from, to)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const AGENT_CHECK_PATH = "/agent/check"
+
+type AgentApi struct {
+}
+
+func NewAgentApi() *AgentApi {
+	return &AgentApi{}
+}
+
+func (api *AgentApi) Check(ctx *context.Context, httpClient *HttpClient, agentUrl string, token string, probeName string, responseDTO any) *exceptions.ApiError {
+	method := http.MethodGet
+	requestUrl := strings.TrimSuffix(agentUrl, "/") + AGENT_CHECK_PATH + "?probe=" + url.QueryEscape(probeName)
+
+	headers := make(map[string]string)
+	headers["Authorization"] = "Bearer " + token
+
+	return executeRequest(ctx, httpClient, method, requestUrl, &headers, nil, responseDTO)
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -14,22 +13,28 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
+type RawResponse struct {
+	Status int
+	Body   []byte
+}
+
 func logRequest(ctx *context.Context, request *http.Request, requestBody []byte) {
 	if log.IsLevelEnabled(log.DEBUG) {
 		requestBodyLength := len(requestBody)
 
-		log.Debug(ctx).Msg(fmt.Sprintf("---> %s %s %s", request.Method, request.URL, request.Proto))
+		log.Debug(ctx).Msg(fmt.Sprintf("---> %s %s %s", request.Method, log.RedactUrl(request.URL), request.Proto))
 		for headerName, headerValues := range request.Header {
-			headerValue := headerValues[constants.ZERO]
+			headerValue := log.RedactHeader(headerName, headerValues[constants.ZERO])
 			log.Debug(ctx).Msg(fmt.Sprintf("%s: %s", headerName, headerValue))
 		}
 
 		if requestBodyLength > 0 {
-			log.Debug(ctx).Msg(string(requestBody))
+			log.Debug(ctx).Msg(log.RedactBody(requestBody))
 		}
 
 		log.Debug(ctx).Msg(fmt.Sprintf("---> END HTTP (%d-byte body)", requestBodyLength))
@@ -42,67 +47,33 @@ func logResponse(ctx *context.Context, start *time.Time, end *time.Time, respons
 
 		log.Debug(ctx).Msg(fmt.Sprintf("<--- %s %s (%s)", response.Proto, response.Status, diff))
 		for headerName, headerValues := range response.Header {
-			headerValue := headerValues[constants.ZERO]
+			headerValue := log.RedactHeader(headerName, headerValues[constants.ZERO])
 			log.Debug(ctx).Msg(fmt.Sprintf("%s: %s", headerName, headerValue))
 		}
 
-		log.Debug(ctx).Msg(string(responseBody))
+		log.Debug(ctx).Msg(log.RedactBody(responseBody))
 		log.Debug(ctx).Msg(fmt.Sprintf("<--- END HTTP (%d-byte body)", len(responseBody)))
 	}
 }
 
-func executeRequest(ctx *context.Context, method string, requestUrl string, timeout time.Duration, headers *map[string]string, requestDTO any, responseDTO any) *exceptions.ApiError {
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
-	}
-
-	client := &http.Client{
-		Timeout:   timeout,
-		Transport: transport,
-	}
-
-	var requestBody []byte
+func sendRequest(ctx *context.Context, httpClient *HttpClient, method string, requestUrl string, headers *map[string]string, hasBody bool, requestBody []byte) (*http.Response, []byte, *exceptions.ApiError) {
 	var reader io.Reader
-	var err error
-
-	if requestDTO != nil {
-		contentType := (*headers)["Content-Type"]
-
-		if strings.Contains(contentType, "/xml") {
-			requestBody, err = xml.Marshal(requestDTO)
-
-		} else if strings.Contains(contentType, "/x-www-form-urlencoded") {
-			requestBody = []byte(requestDTO.(string))
-
-		} else {
-			requestBody, err = json.Marshal(requestDTO)
-		}
-
-		if err != nil {
-			message := fmt.Sprintf("Error during marshal body request: %s", err.Error())
-			log.Error(ctx).Msg(message)
-
-			return &exceptions.ApiError{
-				Message: message,
-			}
-		}
-
+	if hasBody {
 		reader = bytes.NewReader(requestBody)
 	}
 
-	request, err := http.NewRequest(method, requestUrl, reader)
+	request, err := http.NewRequestWithContext(*ctx, method, requestUrl, reader)
 	if err != nil {
+		err = redactUrlError(err)
 		message := fmt.Sprintf("Error on creating request: %s", err.Error())
 		log.Error(ctx).Msg(message)
 
-		return &exceptions.ApiError{
+		return nil, nil, &exceptions.ApiError{
 			Message: message,
 		}
 	}
 
-	if requestDTO != nil && utils.IsEmptyStr((*headers)["Content-Type"]) {
+	if hasBody && utils.IsEmptyStr((*headers)["Content-Type"]) {
 		request.Header.Set("Content-Type", "application/json")
 	}
 
@@ -121,12 +92,14 @@ func executeRequest(ctx *context.Context, method string, requestUrl string, time
 	logRequest(ctx, request, requestBody)
 
 	start := time.Now()
-	response, err := client.Do(request)
+	response, err := httpClient.client.Do(request)
 	if err != nil {
+		err = redactUrlError(err)
 		message := fmt.Sprintf("Error on sending request: %s", err.Error())
 		log.Error(ctx).Msg(message)
 
-		return &exceptions.ApiError{
+		return nil, nil, &exceptions.ApiError{
+			Err:     err,
 			Message: message,
 		}
 	}
@@ -138,7 +111,8 @@ func executeRequest(ctx *context.Context, method string, requestUrl string, time
 		message := fmt.Sprintf("Error on reading response body: %s", err.Error())
 		log.Error(ctx).Msg(message)
 
-		return &exceptions.ApiError{
+		return response, nil, &exceptions.ApiError{
+			Err:     err,
 			Message: message,
 			Status:  response.StatusCode,
 		}
@@ -147,6 +121,63 @@ func executeRequest(ctx *context.Context, method string, requestUrl string, time
 	end := time.Now()
 	logResponse(ctx, &start, &end, response, responseBody)
 
+	return response, responseBody, nil
+}
+
+func executeRequest(ctx *context.Context, httpClient *HttpClient, method string, requestUrl string, headers *map[string]string, requestDTO any, responseDTO any) *exceptions.ApiError {
+	var requestBody []byte
+	var err error
+
+	if requestDTO != nil {
+		contentType := (*headers)["Content-Type"]
+
+		if rawBody, ok := requestDTO.([]byte); ok {
+			requestBody = rawBody
+
+		} else if strings.Contains(contentType, "/xml") {
+			requestBody, err = xml.Marshal(requestDTO)
+
+		} else if strings.Contains(contentType, "/x-www-form-urlencoded") {
+			requestBody = []byte(requestDTO.(string))
+
+		} else {
+			requestBody, err = json.Marshal(requestDTO)
+		}
+
+		if err != nil {
+			message := fmt.Sprintf("Error during marshal body request: %s", err.Error())
+			log.Error(ctx).Msg(message)
+
+			return &exceptions.ApiError{
+				Message: message,
+			}
+		}
+	}
+
+	attempts := httpClient.maxAttempts(method)
+
+	var response *http.Response
+	var responseBody []byte
+	var erra *exceptions.ApiError
+
+	for attempt := constants.ONE; ; attempt++ {
+		response, responseBody, erra = sendRequest(ctx, httpClient, method, requestUrl, headers, requestDTO != nil, requestBody)
+		if attempt >= attempts || !isRetryable(response, erra) {
+			break
+		}
+
+		backoff := httpClient.backoff(attempt, response)
+		log.Warn(ctx).Msg(fmt.Sprintf("Retrying %s %s in %s (attempt %d/%d)", method, log.RedactRawUrl(requestUrl), backoff, attempt+1, attempts))
+
+		if !sleep(ctx, backoff) {
+			break
+		}
+	}
+
+	if erra != nil {
+		return erra
+	}
+
 	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
 		apiError := &exceptions.ApiError{
 			ResponseBody: string(responseBody),
@@ -159,7 +190,11 @@ func executeRequest(ctx *context.Context, method string, requestUrl string, time
 	if responseDTO != nil {
 		responseContentType := response.Header.Get("Content-Type")
 
-		if strings.Contains(responseContentType, "/xml") {
+		if rawResponse, ok := responseDTO.(*RawResponse); ok {
+			rawResponse.Status = response.StatusCode
+			rawResponse.Body = responseBody
+
+		} else if strings.Contains(responseContentType, "/xml") {
 			err = xml.Unmarshal(responseBody, &responseDTO)
 		} else if strings.Contains(responseContentType, "text/") {
 			if str, ok := responseDTO.(*string); ok {
@@ -184,3 +219,13 @@ func executeRequest(ctx *context.Context, method string, requestUrl string, time
 
 	return nil
 }
+
+// redactUrlError masks the URL that net/http embeds in its errors, it may carry credentials in the path
+func redactUrlError(err error) error {
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		urlError.URL = log.RedactRawUrl(urlError.URL)
+	}
+
+	return err
+}
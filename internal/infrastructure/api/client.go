@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/network"
+	"net/http"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT                 = 10 * time.Second
+	DEFAULT_MAX_IDLE_CONNS          = 100
+	DEFAULT_MAX_IDLE_CONNS_PER_HOST = 2
+	DEFAULT_IDLE_CONN_TIMEOUT       = 90 * time.Second
+	DEFAULT_KEEP_ALIVE              = 30 * time.Second
+	TLS_HANDSHAKE_TIMEOUT           = 10 * time.Second
+	EXPECT_CONTINUE_TIMEOUT         = 1 * time.Second
+)
+
+type HttpClient struct {
+	client *http.Client
+	retry  config.Retry
+}
+
+func NewHttpClient(timeout time.Duration, bind config.Bind, clientConfig config.HttpClient) (*HttpClient, error) {
+	if timeout == 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+
+	dialer, err := network.NewDialer(bind, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer.SetKeepAlive(valueOrDefault(clientConfig.KeepAlive, DEFAULT_KEEP_ALIVE))
+
+	tlsConfig, err := network.NewTlsConfig(clientConfig.Tls)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyFunc, err := network.NewProxyFunc(clientConfig.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxyFunc,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          valueOrDefault(clientConfig.MaxIdleConns, DEFAULT_MAX_IDLE_CONNS),
+		MaxIdleConnsPerHost:   valueOrDefault(clientConfig.MaxIdleConnsPerHost, DEFAULT_MAX_IDLE_CONNS_PER_HOST),
+		MaxConnsPerHost:       clientConfig.MaxConnsPerHost,
+		IdleConnTimeout:       valueOrDefault(clientConfig.IdleConnTimeout, DEFAULT_IDLE_CONN_TIMEOUT),
+		TLSHandshakeTimeout:   TLS_HANDSHAKE_TIMEOUT,
+		ExpectContinueTimeout: EXPECT_CONTINUE_TIMEOUT,
+		DisableKeepAlives:     clientConfig.FreshConnections,
+		TLSClientConfig:       tlsConfig,
+	}
+
+	return &HttpClient{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+		retry: clientConfig.Retry,
+	}, nil
+}
+
+func (httpClient *HttpClient) CloseIdleConnections() {
+	httpClient.client.CloseIdleConnections()
+}
+
+func valueOrDefault[T comparable](value T, defaultValue T) T {
+	var zero T
+	if value == zero {
+		return defaultValue
+	}
+
+	return value
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
 	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
 	"net/http"
@@ -14,29 +15,36 @@ func NewFetcherApi() *FetcherApi {
 	return &FetcherApi{}
 }
 
-func (api *FetcherApi) GetPublicIp(ctx *context.Context) (string, *exceptions.ApiError) {
+func (api *FetcherApi) GetPublicIp(ctx *context.Context, httpClient *HttpClient, fetcherConfig config.PublicIPFetcher) (string, *exceptions.ApiError) {
 	method := http.MethodGet
-	fetcherConfig := config.ApplicationConfig.Application.DNSUpdater.PublicIPFetcher
 	requestUrl := fetcherConfig.Url
-	timeout := fetcherConfig.Timeout
 	responseStr := ""
 
 	headers := make(map[string]string)
 	headers["Accept"] = "plain/text"
 
-	erra := executeRequest(ctx, method, requestUrl, timeout, &headers, nil, &responseStr)
+	erra := executeRequest(ctx, httpClient, method, requestUrl, &headers, nil, &responseStr)
 	return responseStr, erra
 }
 
-func (api *FetcherApi) Fetch(ctx *context.Context) *exceptions.ApiError {
-	method := http.MethodGet
-	fetcherConfig := config.ApplicationConfig.Application.ISPFallbackUpdater.PortFetcher
-	requestUrl := fetcherConfig.Url
-	host := fetcherConfig.Host
-	timeout := fetcherConfig.Timeout
+func (api *FetcherApi) Probe(ctx *context.Context, httpClient *HttpClient, method string, requestUrl string, host string) (int, []byte, *exceptions.ApiError) {
+	if utils.IsEmptyStr(method) {
+		method = http.MethodGet
+	}
 
 	headers := make(map[string]string)
-	headers["Host"] = host
+	headers["Accept"] = "*/*"
+
+	if utils.IsNotEmptyStr(host) {
+		headers["Host"] = host
+	}
+
+	var rawResponse RawResponse
+
+	erra := executeRequest(ctx, httpClient, method, requestUrl, &headers, nil, &rawResponse)
+	if erra != nil {
+		return erra.Status, []byte(erra.ResponseBody), erra
+	}
 
-	return executeRequest(ctx, method, requestUrl, timeout, &headers, nil, nil)
+	return rawResponse.Status, rawResponse.Body, nil
 }
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	DEFAULT_INITIAL_BACKOFF = 200 * time.Millisecond
+	DEFAULT_MAX_BACKOFF     = 5 * time.Second
+)
+
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+var retryableStatus = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+func (httpClient *HttpClient) maxAttempts(method string) int {
+	if !slices.Contains(idempotentMethods, method) {
+		return constants.ONE
+	}
+
+	return max(httpClient.retry.MaxAttempts, constants.ONE)
+}
+
+func (httpClient *HttpClient) backoff(attempt int, response *http.Response) time.Duration {
+	retry := httpClient.retry
+	initialBackoff := valueOrDefault(retry.InitialBackoff, DEFAULT_INITIAL_BACKOFF)
+	maxBackoff := valueOrDefault(retry.MaxBackoff, DEFAULT_MAX_BACKOFF)
+
+	if retryAfter, ok := getRetryAfter(response); ok {
+		return min(retryAfter, maxBackoff)
+	}
+
+	backoff := float64(initialBackoff) * math.Pow(2, float64(attempt-constants.ONE))
+	backoff = math.Min(backoff, float64(maxBackoff))
+
+	if retry.Jitter > 0 {
+		jitter := math.Min(retry.Jitter, 1)
+		backoff = backoff * (1 - jitter + rand.Float64()*2*jitter)
+	}
+
+	return time.Duration(backoff)
+}
+
+func getRetryAfter(response *http.Response) (time.Duration, bool) {
+	if response == nil {
+		return 0, false
+	}
+
+	retryAfter := response.Header.Get("Retry-After")
+	seconds, err := strconv.Atoi(retryAfter)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+func isRetryable(response *http.Response, erra *exceptions.ApiError) bool {
+	if erra != nil {
+		return erra.Err != nil && isRetryableError(erra.Err)
+	}
+
+	return response != nil && slices.Contains(retryableStatus, response.StatusCode)
+}
+
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+	var verificationError *tls.CertificateVerificationError
+
+	if errors.As(err, &unknownAuthorityError) || errors.As(err, &hostnameError) ||
+		errors.As(err, &certificateInvalidError) || errors.As(err, &verificationError) {
+		return false
+	}
+
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return dnsError.IsTimeout || dnsError.IsTemporary
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netError net.Error
+	return errors.As(err, &netError) && netError.Timeout()
+}
+
+func sleep(ctx *context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-(*ctx).Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMaxAttempts(t *testing.T) {
+	tests := []struct {
+		method      string
+		maxAttempts int
+		expected    int
+	}{
+		{http.MethodGet, 3, 3},
+		{http.MethodPut, 3, 3},
+		{http.MethodPost, 3, 1},
+		{http.MethodPatch, 3, 1},
+		{http.MethodGet, 0, 1},
+	}
+
+	for _, test := range tests {
+		httpClient := &HttpClient{retry: config.Retry{MaxAttempts: test.maxAttempts}}
+		if attempts := httpClient.maxAttempts(test.method); attempts != test.expected {
+			t.Errorf("maxAttempts(%s) with %d = %d, expected %d", test.method, test.maxAttempts, attempts, test.expected)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	retryAfter := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	longRetryAfter := &http.Response{Header: http.Header{"Retry-After": []string{"120"}}}
+	invalidRetryAfter := &http.Response{Header: http.Header{"Retry-After": []string{"soon"}}}
+
+	tests := []struct {
+		name     string
+		retry    config.Retry
+		attempt  int
+		response *http.Response
+		expected time.Duration
+	}{
+		{"defaults first attempt", config.Retry{}, 1, nil, DEFAULT_INITIAL_BACKOFF},
+		{"exponential", config.Retry{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Minute}, 4, nil, 800 * time.Millisecond},
+		{"capped", config.Retry{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}, 5, nil, 3 * time.Second},
+		{"retry after", config.Retry{}, 1, retryAfter, 2 * time.Second},
+		{"retry after capped", config.Retry{MaxBackoff: 10 * time.Second}, 1, longRetryAfter, 10 * time.Second},
+		{"invalid retry after", config.Retry{InitialBackoff: time.Second}, 1, invalidRetryAfter, time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := &HttpClient{retry: test.retry}
+			if backoff := httpClient.backoff(test.attempt, test.response); backoff != test.expected {
+				t.Errorf("backoff = %s, expected %s", backoff, test.expected)
+			}
+		})
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	httpClient := &HttpClient{retry: config.Retry{InitialBackoff: time.Second, MaxBackoff: time.Minute, Jitter: 0.2}}
+
+	for i := 0; i < 100; i++ {
+		backoff := httpClient.backoff(1, nil)
+		if backoff < 800*time.Millisecond || backoff > 1200*time.Millisecond {
+			t.Fatalf("backoff %s outside the jitter range", backoff)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	dialError := func(errno syscall.Errno) error {
+		return &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: errno}}}
+	}
+
+	tests := []struct {
+		name     string
+		response *http.Response
+		erra     *exceptions.ApiError
+		expected bool
+	}{
+		{"ok status", &http.Response{StatusCode: http.StatusOK}, nil, false},
+		{"not found", &http.Response{StatusCode: http.StatusNotFound}, nil, false},
+		{"throttled", &http.Response{StatusCode: http.StatusTooManyRequests}, nil, true},
+		{"bad gateway", &http.Response{StatusCode: http.StatusBadGateway}, nil, true},
+		{"error without cause", nil, &exceptions.ApiError{Message: "invalid request"}, false},
+		{"connection refused", nil, &exceptions.ApiError{Err: dialError(syscall.ECONNREFUSED)}, true},
+		{"connection reset", nil, &exceptions.ApiError{Err: dialError(syscall.ECONNRESET)}, true},
+		{"unexpected eof", nil, &exceptions.ApiError{Err: fmt.Errorf("read: %w", io.ErrUnexpectedEOF)}, true},
+		{"timeout", nil, &exceptions.ApiError{Err: &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}}, true},
+		{"canceled", nil, &exceptions.ApiError{Err: fmt.Errorf("request: %w", context.Canceled)}, false},
+		{"unknown authority", nil, &exceptions.ApiError{Err: &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}}, false},
+		{"dns not found", nil, &exceptions.ApiError{Err: &net.DNSError{Err: "no such host", IsNotFound: true}}, false},
+		{"dns temporary", nil, &exceptions.ApiError{Err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}}, true},
+		{"other", nil, &exceptions.ApiError{Err: errors.New("boom")}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if retryable := isRetryable(test.response, test.erra); retryable != test.expected {
+				t.Errorf("isRetryable = %t, expected %t", retryable, test.expected)
+			}
+		})
+	}
+}
+
+func TestRedactUrlError(t *testing.T) {
+	err := redactUrlError(&url.Error{Op: "Post", URL: "https://api.telegram.org/bot1:secret/sendMessage", Err: errors.New("timeout")})
+
+	if expected := `Post "https://api.telegram.org/botREDACTED/sendMessage": timeout`; err.Error() != expected {
+		t.Errorf("redacted error = %s, expected %s", err.Error(), expected)
+	}
+}
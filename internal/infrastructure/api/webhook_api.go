@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const DEFAULT_WEBHOOK_TIMEOUT = 10 * time.Second
+
+// WebhookApi pools one client per timeout and client settings, shared by webhook actions and notification channels
+type WebhookApi struct {
+	clients map[string]*HttpClient
+	mutex   sync.Mutex
+}
+
+func NewWebhookApi() *WebhookApi {
+	return &WebhookApi{
+		clients: make(map[string]*HttpClient),
+	}
+}
+
+func (api *WebhookApi) Call(ctx *context.Context, method string, requestUrl string, timeout time.Duration, clientConfig config.HttpClient, headers map[string]string, body string) *exceptions.ApiError {
+	if utils.IsEmptyStr(method) {
+		method = http.MethodPost
+	}
+
+	httpClient, err := api.Client(timeout, clientConfig)
+	if err != nil {
+		return &exceptions.ApiError{
+			Err:     err,
+			Message: err.Error(),
+		}
+	}
+
+	requestHeaders := make(map[string]string)
+	for key, value := range headers {
+		requestHeaders[key] = value
+	}
+
+	var requestDTO any
+	if utils.IsNotEmptyStr(body) {
+		requestDTO = []byte(body)
+	}
+
+	return executeRequest(ctx, httpClient, method, requestUrl, &requestHeaders, requestDTO, nil)
+}
+
+// Client builds or reuses the client of the given settings, calling it at startup validates the proxy and tls options
+func (api *WebhookApi) Client(timeout time.Duration, clientConfig config.HttpClient) (*HttpClient, error) {
+	if timeout == 0 {
+		timeout = DEFAULT_WEBHOOK_TIMEOUT
+	}
+
+	// the settings hold slices, so the key is their printed form
+	key := fmt.Sprintf("%s %+v", timeout, clientConfig)
+
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	httpClient, exists := api.clients[key]
+	if exists {
+		return httpClient, nil
+	}
+
+	httpClient, err := NewHttpClient(timeout, config.Bind{}, clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	api.clients[key] = httpClient
+	return httpClient, nil
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookCallUsesConfiguredProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		proxied = request.URL.String()
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	webhookApi := NewWebhookApi()
+	clientConfig := config.HttpClient{Proxy: config.Proxy{Url: proxy.URL}}
+
+	ctx := context.Background()
+	erra := webhookApi.Call(&ctx, http.MethodPost, "http://hooks.example.test/notify", time.Second, clientConfig, nil, `{"text":"up"}`)
+	if erra != nil {
+		t.Fatal(erra)
+	}
+
+	if proxied != "http://hooks.example.test/notify" {
+		t.Errorf("request not sent through the proxy, proxy saw %q", proxied)
+	}
+}
+
+func TestWebhookClient(t *testing.T) {
+	webhookApi := NewWebhookApi()
+	proxied := config.HttpClient{Proxy: config.Proxy{Url: "http://proxy.example.com:3128", NoProxy: []string{"localhost"}}}
+
+	direct, err := webhookApi.Client(0, config.HttpClient{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := webhookApi.Client(time.Second, proxied)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _ := webhookApi.Client(time.Second, proxied)
+	if first != second {
+		t.Error("same settings built a second client")
+	}
+
+	if first == direct {
+		t.Error("proxy settings ignored when pooling clients")
+	}
+
+	_, err = webhookApi.Client(time.Second, config.HttpClient{Proxy: config.Proxy{Url: "ftp://proxy.example.com"}})
+	if err == nil {
+		t.Error("unsupported proxy scheme accepted")
+	}
+}
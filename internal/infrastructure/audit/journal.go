@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/rotate"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RESULT_SUCCESS = "success"
+	RESULT_FAILURE = "failure"
+
+	DEFAULT_PATH  = "logs/audit.jsonl"
+	DEFAULT_LIMIT = 100
+
+	MAX_LINE_SIZE = 1024 * 1024
+)
+
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Trigger    string    `json:"trigger"`
+	Actor      string    `json:"actor,omitempty"`
+	Group      string    `json:"group,omitempty"`
+	Link       string    `json:"link,omitempty"`
+	CycleId    string    `json:"cycleId,omitempty"`
+	FailoverId string    `json:"failoverId,omitempty"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	OldValue   string    `json:"oldValue,omitempty"`
+	NewValue   string    `json:"newValue"`
+	Result     string    `json:"result"`
+	Code       string    `json:"code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type Filter struct {
+	Since    time.Time
+	Action   string
+	Resource string
+	Group    string
+	Limit    int
+}
+
+type Journal struct {
+	writer *rotate.Writer
+	mutex  sync.Mutex
+}
+
+func NewJournal(auditConfig config.Audit) (*Journal, error) {
+	if !auditConfig.Enabled {
+		return &Journal{}, nil
+	}
+
+	fileConfig := auditConfig.File
+	writer, err := rotate.NewWriter(Path(auditConfig), fileConfig.MaxSize, fileConfig.Interval, fileConfig.MaxAge, fileConfig.MaxBackups)
+	if err != nil {
+		return nil, errors.New("Error on opening audit journal: " + err.Error())
+	}
+
+	return &Journal{
+		writer: writer,
+	}, nil
+}
+
+func Path(auditConfig config.Audit) string {
+	if utils.IsEmptyStr(auditConfig.File.Path) {
+		return DEFAULT_PATH
+	}
+
+	return auditConfig.File.Path
+}
+
+func (journal *Journal) Record(entry Entry) error {
+	if journal.writer == nil {
+		return nil
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	_, err = journal.writer.Write(append(data, '\n'))
+	return err
+}
+
+// Query reads the journal and its rotated files, returning the most recent matching entries, newest first
+func Query(path string, filter Filter) ([]Entry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+
+	var entries []Entry
+	for _, file := range rotate.Files(path) {
+		fileEntries, err := readEntries(file, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, fileEntries...)
+		if len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+	}
+
+	for left, right := 0, len(entries)-1; left < right; left, right = left+1, right-1 {
+		entries[left], entries[right] = entries[right], entries[left]
+	}
+
+	return entries, nil
+}
+
+func readEntries(path string, filter Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), MAX_LINE_SIZE)
+
+	for scanner.Scan() {
+		var entry Entry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+func (filter Filter) matches(entry Entry) bool {
+	if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+		return false
+	}
+
+	if utils.IsNotEmptyStr(filter.Action) && entry.Action != filter.Action {
+		return false
+	}
+
+	if utils.IsNotEmptyStr(filter.Group) && entry.Group != filter.Group {
+		return false
+	}
+
+	return utils.IsEmptyStr(filter.Resource) || strings.Contains(entry.Resource, filter.Resource)
+}
+
+// ParseSince accepts either a RFC 3339 timestamp or a duration relative to now, like 24h
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if utils.IsEmptyStr(value) {
+		return time.Time{}, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		return now.Add(-duration), nil
+	}
+
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("Invalid since, expected a duration or RFC 3339 time: " + value)
+	}
+
+	return since, nil
+}
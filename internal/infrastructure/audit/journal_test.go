@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/sink"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFilterMatches(t *testing.T) {
+	base := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	entry := Entry{Time: base, Action: "route53-record", Resource: "Z2W4TJW8B6Z0T/example.com", Group: "home"}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{"empty", Filter{}, true},
+		{"since before", Filter{Since: base.Add(-time.Hour)}, true},
+		{"since after", Filter{Since: base.Add(time.Hour)}, false},
+		{"action", Filter{Action: "route53-record"}, true},
+		{"other action", Filter{Action: "asg-capacity"}, false},
+		{"resource substring", Filter{Resource: "example.com"}, true},
+		{"other resource", Filter{Resource: "example.net"}, false},
+		{"group", Filter{Group: "home"}, true},
+		{"other group", Filter{Group: "office"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if matches := test.filter.matches(entry); matches != test.expected {
+				t.Errorf("matches = %t, expected %t", matches, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+		failed   bool
+	}{
+		{"", time.Time{}, false},
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"2024-05-01T10:00:00Z", time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		since, err := ParseSince(test.value, now)
+		if (err != nil) != test.failed || !since.Equal(test.expected) {
+			t.Errorf("ParseSince(%s) = %s, %v", test.value, since, err)
+		}
+	}
+}
+
+func TestQueryNewestFirstAcrossRotatedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	journal, err := NewJournal(config.Audit{Enabled: true, File: sink.File{Path: path, MaxSize: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	for index, action := range []string{"asg-capacity", "route53-record", "asg-capacity", "route53-record"} {
+		err = journal.Record(Entry{Time: base.Add(time.Duration(index) * time.Minute), Action: action, Resource: "r", Result: RESULT_SUCCESS})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// every entry rotates the file, backup names have millisecond resolution
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	entries, err := Query(path, Filter{Action: "route53-record"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || !entries[0].Time.Equal(base.Add(3*time.Minute)) || !entries[1].Time.Equal(base.Add(time.Minute)) {
+		t.Errorf("unexpected entries %v", entries)
+	}
+
+	limited, err := Query(path, Filter{Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(limited) != 3 || !limited[0].Time.Equal(base.Add(3*time.Minute)) || !limited[2].Time.Equal(base.Add(time.Minute)) {
+		t.Errorf("unexpected limited entries %v", limited)
+	}
+}
+
+func TestQuerySkipsInvalidLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	err := os.WriteFile(path, []byte("not json\n{\"action\":\"webhook\",\"time\":\"2024-05-01T00:00:00Z\"}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Query(path, Filter{})
+	if err != nil || len(entries) != 1 || entries[0].Action != "webhook" {
+		t.Errorf("unexpected entries %v, error %v", entries, err)
+	}
+}
+
+func TestDisabledJournalIgnoresRecords(t *testing.T) {
+	journal, err := NewJournal(config.Audit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = journal.Record(Entry{Action: "webhook"}); err != nil {
+		t.Errorf("disabled journal failed: %v", err)
+	}
+}
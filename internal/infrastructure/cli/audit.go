@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/audit"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"flag"
+	"os"
+	"time"
+)
+
+const AUDIT_COMMAND = "audit"
+
+// RunAudit prints the most recent audit entries as JSON lines, newest first
+func RunAudit(args []string) error {
+	flags := flag.NewFlagSet(AUDIT_COMMAND, flag.ContinueOnError)
+	limit := flags.Int("limit", audit.DEFAULT_LIMIT, "maximum number of entries")
+	since := flags.String("since", "", "duration like 24h or RFC 3339 time")
+	action := flags.String("action", "", "filter by action type")
+	resource := flags.String("resource", "", "filter by resource substring")
+	group := flags.String("group", "", "filter by fallback group")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	sinceTime, err := audit.ParseSince(*since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	entries, err := audit.Query(audit.Path(config.ApplicationConfig.Audit), audit.Filter{
+		Since:    sinceTime,
+		Action:   *action,
+		Resource: *resource,
+		Group:    *group,
+		Limit:    *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, entry := range entries {
+		err = encoder.Encode(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	SCHEDULED_COMMAND = "scheduled"
+
+	SCHEDULED_LIST       = "list"
+	SCHEDULED_CANCEL     = "cancel"
+	SCHEDULED_RESCHEDULE = "reschedule"
+
+	TOKEN_ENV    = "HELPER_TOKEN"
+	PASSWORD_ENV = "HELPER_PASSWORD"
+
+	REQUEST_TIMEOUT = 30 * time.Second
+)
+
+type scheduledClient struct {
+	baseUrl    string
+	token      string
+	username   string
+	httpClient *http.Client
+}
+
+// RunScheduled manages the pending auto scaling group changes of a running helper through its HTTP server:
+// scheduled [list], scheduled cancel -group g -id i, scheduled reschedule -group g -id i -delay 30m
+func RunScheduled(args []string) error {
+	operation := SCHEDULED_LIST
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		operation = args[0]
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet(SCHEDULED_COMMAND, flag.ContinueOnError)
+	serverUrl := flags.String("url", defaultServerUrl(), "helper server url")
+	token := flags.String("token", os.Getenv(TOKEN_ENV), "bearer token, defaults to "+TOKEN_ENV)
+	username := flags.String("user", "", "basic auth user, the password is read from "+PASSWORD_ENV)
+	caFile := flags.String("ca-file", "", "CA certificate of the server")
+	group := flags.String("group", "", "fallback group")
+	id := flags.String("id", "", "scheduled capacity id")
+	delay := flags.Duration("delay", 0, "reschedule to now plus delay")
+	at := flags.String("at", "", "reschedule to RFC 3339 time")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	client, err := newScheduledClient(*serverUrl, *token, *username, *caFile)
+	if err != nil {
+		return err
+	}
+
+	switch operation {
+	case SCHEDULED_LIST:
+		return client.list()
+
+	case SCHEDULED_CANCEL:
+		if utils.IsEmptyStr(*group) || utils.IsEmptyStr(*id) {
+			return errors.New("Cancel requires -group and -id")
+		}
+
+		query := url.Values{"group": {*group}, "id": {*id}}
+		return client.print(http.MethodDelete, "/scheduled?"+query.Encode(), nil)
+
+	case SCHEDULED_RESCHEDULE:
+		if utils.IsEmptyStr(*group) || utils.IsEmptyStr(*id) {
+			return errors.New("Reschedule requires -group and -id")
+		}
+
+		body := map[string]any{"group": *group, "id": *id}
+		if utils.IsNotEmptyStr(*at) {
+			executeAt, err := time.Parse(time.RFC3339, *at)
+			if err != nil {
+				return errors.New("Invalid at, expected RFC 3339 time: " + *at)
+			}
+
+			body["executeAt"] = executeAt
+		} else if *delay > 0 {
+			body["delay"] = delay.String()
+		} else {
+			return errors.New("Reschedule requires -delay or -at")
+		}
+
+		return client.print(http.MethodPost, "/scheduled", body)
+	}
+
+	return fmt.Errorf("Unknown %s operation: %s", SCHEDULED_COMMAND, operation)
+}
+
+// defaultServerUrl targets the local server from the same configuration
+func defaultServerUrl() string {
+	serverConfig := config.ApplicationConfig.Server
+
+	host, port, err := net.SplitHostPort(serverConfig.Listening)
+	if err != nil {
+		return ""
+	}
+
+	if utils.IsEmptyStr(host) || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	scheme := "http"
+	if utils.IsNotEmptyStr(serverConfig.Tls.CertFile) {
+		scheme = "https"
+	}
+
+	return scheme + "://" + net.JoinHostPort(host, port) + strings.TrimSuffix(serverConfig.ContextPath, constants.SLASH)
+}
+
+func newScheduledClient(serverUrl string, token string, username string, caFile string) (*scheduledClient, error) {
+	if utils.IsEmptyStr(serverUrl) {
+		return nil, errors.New("Missing -url, server listening address is not configured")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if utils.IsNotEmptyStr(caFile) {
+		caPem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.New("Error on reading CA file: " + err.Error())
+		}
+
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caPem) {
+			return nil, errors.New("No certificate found in CA file " + caFile)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs}
+	}
+
+	return &scheduledClient{
+		baseUrl:  strings.TrimSuffix(serverUrl, constants.SLASH),
+		token:    token,
+		username: username,
+		httpClient: &http.Client{
+			Timeout:   REQUEST_TIMEOUT,
+			Transport: transport,
+		},
+	}, nil
+}
+
+func (client *scheduledClient) list() error {
+	var response struct {
+		Scheduled []json.RawMessage `json:"scheduled"`
+	}
+
+	data, err := client.do(http.MethodGet, "/scheduled", nil)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return err
+	}
+
+	for _, scheduled := range response.Scheduled {
+		fmt.Println(string(scheduled))
+	}
+
+	return nil
+}
+
+func (client *scheduledClient) print(method string, path string, body any) error {
+	data, err := client.do(method, path, body)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(strings.TrimSpace(string(data)))
+	return nil
+}
+
+func (client *scheduledClient) do(method string, path string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, client.baseUrl+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if utils.IsNotEmptyStr(client.token) {
+		request.Header.Set("Authorization", "Bearer "+client.token)
+	} else if utils.IsNotEmptyStr(client.username) {
+		request.SetBasicAuth(client.username, os.Getenv(PASSWORD_ENV))
+	}
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		var apiError struct {
+			Message string `json:"message"`
+		}
+
+		if json.Unmarshal(data, &apiError) == nil && utils.IsNotEmptyStr(apiError.Message) {
+			return nil, fmt.Errorf("Server returned %d: %s", response.StatusCode, apiError.Message)
+		}
+
+		return nil, fmt.Errorf("Server returned %d", response.StatusCode)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/uptime"
+	"flag"
+	"os"
+	"time"
+)
+
+const UPTIME_COMMAND = "uptime"
+
+// RunUptime prints the outage report of each fallback group for the requested period
+func RunUptime(args []string) error {
+	flags := flag.NewFlagSet(UPTIME_COMMAND, flag.ContinueOnError)
+	month := flags.String("month", "", "report month like 2024-05, defaults to the current month")
+	from := flags.String("from", "", "duration like 720h or RFC 3339 time")
+	to := flags.String("to", "", "RFC 3339 time, defaults to now")
+	group := flags.String("group", "", "filter by fallback group")
+	format := flags.String("format", uptime.FORMAT_JSON, "output format, json or csv")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *format != uptime.FORMAT_JSON && *format != uptime.FORMAT_CSV {
+		return errors.New("Invalid format: " + *format)
+	}
+
+	fromTime, toTime, err := uptime.ParsePeriod(*month, *from, *to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	reports, err := uptime.Query(uptime.Path(config.ApplicationConfig.Uptime), *group, fromTime, toTime)
+	if err != nil {
+		return err
+	}
+
+	if *format == uptime.FORMAT_CSV {
+		return uptime.WriteCSV(os.Stdout, reports)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(reports)
+}
@@ -0,0 +1,12 @@
+package action
+
+type Type string
+
+const (
+	ROUTE53_RECORD    Type = "route53-record"
+	CLOUDFRONT_ORIGIN Type = "cloudfront-origin"
+	ASG_CAPACITY      Type = "asg-capacity"
+	EC2_INSTANCE      Type = "ec2-instance-state"
+	WEBHOOK           Type = "webhook"
+	DELAY             Type = "delay"
+)
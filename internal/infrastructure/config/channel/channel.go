@@ -0,0 +1,13 @@
+package channel
+
+type Type string
+
+const (
+	WEBHOOK  Type = "webhook"
+	SLACK    Type = "slack"
+	DISCORD  Type = "discord"
+	TELEGRAM Type = "telegram"
+	NTFY     Type = "ntfy"
+	GOTIFY   Type = "gotify"
+	SMTP     Type = "smtp"
+)
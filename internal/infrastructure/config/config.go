@@ -1,93 +1,401 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/action"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/channel"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/event"
 	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/format"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/lease"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/quiet"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/role"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/sink"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+type FallbackAction struct {
+	Type            action.Type `yaml:"type"`
+	ContinueOnError bool        `yaml:"continue-on-error"`
+
+	Record struct {
+		HostedZoneIds []string `yaml:"hosted-zone-ids"`
+		Name          string   `yaml:"name"`
+		Type          string   `yaml:"type"`
+		TTL           int64    `yaml:"ttl"`
+		Value         string   `yaml:"value"`
+	} `yaml:"record"`
+
+	Cloudfront struct {
+		DistributionId string `yaml:"distribution-id"`
+		Origin         string `yaml:"origin"`
+	} `yaml:"cloudfront"`
+
+	AutoScalingGroup struct {
+		Name     string        `yaml:"name"`
+		Capacity int32         `yaml:"capacity"`
+		After    time.Duration `yaml:"after"`
+	} `yaml:"auto-scaling-group"`
+
+	EC2Instance struct {
+		InstanceIds []string `yaml:"instance-ids"`
+		State       string   `yaml:"state"`
+	} `yaml:"ec2-instance"`
+
+	Webhook struct {
+		Url     string            `yaml:"url"`
+		Method  string            `yaml:"method"`
+		Headers map[string]string `yaml:"headers"`
+		Body    string            `yaml:"body"`
+		Timeout time.Duration     `yaml:"timeout"`
+		Client  HttpClient        `yaml:"client"`
+	} `yaml:"webhook"`
+
+	Delay struct {
+		Duration time.Duration `yaml:"duration"`
+	} `yaml:"delay"`
+}
+
+type Bind struct {
+	SourceAddress string `yaml:"source-address"`
+	Interface     string `yaml:"interface"`
+}
+
+type TlsClient struct {
+	InsecureSkipVerify bool     `yaml:"insecure-skip-verify"`
+	ServerName         string   `yaml:"server-name"`
+	CaFile             string   `yaml:"ca-file"`
+	CertFile           string   `yaml:"cert-file"`
+	KeyFile            string   `yaml:"key-file"`
+	PinnedFingerprints []string `yaml:"pinned-fingerprints"`
+}
+
+type Retry struct {
+	MaxAttempts    int           `yaml:"max-attempts"`
+	InitialBackoff time.Duration `yaml:"initial-backoff"`
+	MaxBackoff     time.Duration `yaml:"max-backoff"`
+	Jitter         float64       `yaml:"jitter"`
+}
+
+type Proxy struct {
+	Url      string   `yaml:"url"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	NoProxy  []string `yaml:"no-proxy"`
+}
+
+type AwsRetry struct {
+	Mode        string        `yaml:"mode"`
+	MaxAttempts int           `yaml:"max-attempts"`
+	MaxBackoff  time.Duration `yaml:"max-backoff"`
+}
+
+type AwsEvents struct {
+	Types  []event.Type `yaml:"types"`
+	Source string       `yaml:"source"`
+	Sns    struct {
+		TopicArn string `yaml:"topic-arn"`
+	} `yaml:"sns"`
+	EventBridge struct {
+		BusName string `yaml:"bus-name"`
+	} `yaml:"eventbridge"`
+}
+
+type Metrics struct {
+	Enabled       bool              `yaml:"enabled"`
+	Namespace     string            `yaml:"namespace"`
+	Dimensions    map[string]string `yaml:"dimensions"`
+	FlushInterval time.Duration     `yaml:"flush-interval"`
+	BatchSize     int               `yaml:"batch-size"`
+	MaxBuffer     int               `yaml:"max-buffer"`
+}
+
+type Audit struct {
+	Enabled bool      `yaml:"enabled"`
+	File    sink.File `yaml:"file"`
+}
+
+type ElectionRoute53 struct {
+	HostedZoneId string `yaml:"hosted-zone-id"`
+	RecordName   string `yaml:"record-name"`
+}
+
+type ElectionFile struct {
+	Path string `yaml:"path"`
+}
+
+type Election struct {
+	Enabled       bool            `yaml:"enabled"`
+	Backend       lease.Type      `yaml:"backend"`
+	Id            string          `yaml:"id"`
+	Ttl           time.Duration   `yaml:"ttl"`
+	RenewInterval time.Duration   `yaml:"renew-interval"`
+	MaxClockSkew  time.Duration   `yaml:"max-clock-skew"`
+	Route53       ElectionRoute53 `yaml:"route53"`
+	File          ElectionFile    `yaml:"file"`
+}
+
+type Uptime struct {
+	Enabled bool      `yaml:"enabled"`
+	File    sink.File `yaml:"file"`
+}
+
+type HttpClient struct {
+	MaxIdleConns        int           `yaml:"max-idle-conns"`
+	MaxIdleConnsPerHost int           `yaml:"max-idle-conns-per-host"`
+	MaxConnsPerHost     int           `yaml:"max-conns-per-host"`
+	IdleConnTimeout     time.Duration `yaml:"idle-conn-timeout"`
+	KeepAlive           time.Duration `yaml:"keep-alive"`
+	FreshConnections    bool          `yaml:"fresh-connections"`
+	Tls                 TlsClient     `yaml:"tls"`
+	Retry               Retry         `yaml:"retry"`
+	Proxy               Proxy         `yaml:"proxy"`
+}
+
+type PublicIPFetcher struct {
+	Url     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+	Bind    Bind          `yaml:"bind"`
+	Client  HttpClient    `yaml:"client"`
+}
+
+type DNSRecord struct {
+	HostedZoneIds []string `yaml:"hosted-zone-ids"`
+	Name          string   `yaml:"name"`
+	TTL           int64    `yaml:"ttl"`
+}
+
+type DNSLink struct {
+	Name            string          `yaml:"name"`
+	PublicIPFetcher PublicIPFetcher `yaml:"public-ip-fetcher"`
+	Record          DNSRecord       `yaml:"record"`
+}
+
+type Probe struct {
+	Name    string        `yaml:"name"`
+	Type    probe.Type    `yaml:"type"`
+	Timeout time.Duration `yaml:"timeout"`
+	Bind    Bind          `yaml:"bind"`
+	Client  HttpClient    `yaml:"client"`
+
+	Http struct {
+		Url            string `yaml:"url"`
+		Host           string `yaml:"host"`
+		Method         string `yaml:"method"`
+		ExpectedStatus []int  `yaml:"expected-status"`
+		BodyMatch      string `yaml:"body-match"`
+	} `yaml:"http"`
+
+	Tcp struct {
+		Address string `yaml:"address"`
+	} `yaml:"tcp"`
+
+	Tls struct {
+		Address            string        `yaml:"address"`
+		ServerName         string        `yaml:"server-name"`
+		InsecureSkipVerify bool          `yaml:"insecure-skip-verify"`
+		MinValidity        time.Duration `yaml:"min-validity"`
+	} `yaml:"tls"`
+
+	Dns struct {
+		Name       string   `yaml:"name"`
+		Server     string   `yaml:"server"`
+		RecordType string   `yaml:"record-type"`
+		Expected   []string `yaml:"expected"`
+	} `yaml:"dns"`
+
+	Remote struct {
+		Probe  string `yaml:"probe"`
+		Agents []struct {
+			Url   string `yaml:"url"`
+			Token string `yaml:"token"`
+		} `yaml:"agents"`
+	} `yaml:"remote"`
+}
+
+type SmtpChannel struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+type NotificationChannel struct {
+	Name     string            `yaml:"name"`
+	Type     channel.Type      `yaml:"type"`
+	Url      string            `yaml:"url"`
+	Method   string            `yaml:"method"`
+	Headers  map[string]string `yaml:"headers"`
+	Body     string            `yaml:"body"`
+	Timeout  time.Duration     `yaml:"timeout"`
+	Token    string            `yaml:"token"`
+	ChatId   string            `yaml:"chat-id"`
+	Priority int               `yaml:"priority"`
+	Client   HttpClient        `yaml:"client"`
+	Smtp     SmtpChannel       `yaml:"smtp"`
+}
+
+type NotificationRule struct {
+	Events      []event.Type  `yaml:"events"`
+	Channels    []string      `yaml:"channels"`
+	RateLimit   time.Duration `yaml:"rate-limit"`
+	DedupWindow time.Duration `yaml:"dedup-window"`
+}
+
+type Notifications struct {
+	Channels []NotificationChannel `yaml:"channels"`
+	Rules    []NotificationRule    `yaml:"rules"`
+}
+
+type Schedule struct {
+	CheckInterval time.Duration `yaml:"check-interval"`
+	Cron          string        `yaml:"cron"`
+	Jitter        time.Duration `yaml:"jitter"`
+	DelayFirstRun bool          `yaml:"delay-first-run"`
+}
+
+type QuietWindow struct {
+	Name     string     `yaml:"name"`
+	Days     []string   `yaml:"days"`
+	Start    string     `yaml:"start"`
+	End      string     `yaml:"end"`
+	Timezone string     `yaml:"timezone"`
+	Mode     quiet.Mode `yaml:"mode"`
+}
+
+type FallbackGroup struct {
+	Name     string `yaml:"name"`
+	Schedule `yaml:",inline"`
+
+	Thresholds struct {
+		Failure  int `yaml:"failure"`
+		Recovery int `yaml:"recovery"`
+	} `yaml:"thresholds"`
+
+	DownWhen    probe.Mode    `yaml:"down-when"`
+	DownClasses []probe.Class `yaml:"down-classes"`
+	Probes      []Probe       `yaml:"probes"`
+
+	QuietWindows []QuietWindow `yaml:"quiet-windows"`
+
+	Actions struct {
+		Enable  []FallbackAction `yaml:"enable"`
+		Disable []FallbackAction `yaml:"disable"`
+	} `yaml:"actions"`
+}
+
+// ISPFallbackUpdater is nil when the section is absent, which disables the ISP fallback
+type ISPFallbackUpdater struct {
+	Groups []FallbackGroup `yaml:"groups"`
+}
+
+type ServerTls struct {
+	CertFile          string `yaml:"cert-file"`
+	KeyFile           string `yaml:"key-file"`
+	ClientCaFile      string `yaml:"client-ca-file"`
+	RequireClientCert bool   `yaml:"require-client-cert"`
+}
+
+type AuthToken struct {
+	Name  string    `yaml:"name"`
+	Token string    `yaml:"token"`
+	Role  role.Type `yaml:"role"`
+}
+
+type AuthUser struct {
+	Username     string    `yaml:"username"`
+	PasswordHash string    `yaml:"password-hash"`
+	Role         role.Type `yaml:"role"`
+}
+
+type AuthClient struct {
+	CommonName string    `yaml:"common-name"`
+	Role       role.Type `yaml:"role"`
+}
+
+type ServerAuth struct {
+	Tokens        []AuthToken  `yaml:"tokens"`
+	Users         []AuthUser   `yaml:"users"`
+	Clients       []AuthClient `yaml:"clients"`
+	AnonymousRead bool         `yaml:"anonymous-read"`
+}
+
 type Config struct {
 	Server struct {
-		Listening   string `yaml:"listening"`
-		ContextPath string `yaml:"context-path"`
+		Listening   string     `yaml:"listening"`
+		ContextPath string     `yaml:"context-path"`
+		Tls         ServerTls  `yaml:"tls"`
+		Auth        ServerAuth `yaml:"auth"`
 	} `yaml:"server"`
 
 	Application struct {
 		DNSUpdater struct {
-			CheckInterval time.Duration `yaml:"check-interval"`
-
-			PublicIPFetcher struct {
-				Url     string        `yaml:"url"`
-				Timeout time.Duration `yaml:"timeout"`
-			} `yaml:"public-ip-fetcher"`
-
-			Record struct {
-				HostedZoneIds []string `yaml:"hosted-zone-ids"`
-				Name          string   `yaml:"name"`
-				TTL           int64    `yaml:"ttl"`
-			} `yaml:"record"`
+			Schedule `yaml:",inline"`
+
+			PublicIPFetcher PublicIPFetcher `yaml:"public-ip-fetcher"`
+			Record          DNSRecord       `yaml:"record"`
+			Links           []DNSLink       `yaml:"links"`
 		} `yaml:"dns-updater"`
 
-		ISPFallbackUpdater struct {
-			CheckInterval time.Duration `yaml:"check-interval"`
-
-			PortFetcher struct {
-				Url     string        `yaml:"url"`
-				Host    string        `yaml:"host"`
-				Timeout time.Duration `yaml:"timeout"`
-			} `yaml:"port-fetcher"`
-
-			Record struct {
-				HostedZoneIds []string `yaml:"hosted-zone-ids"`
-				Name          string   `yaml:"name"`
-				TTL           int64    `yaml:"ttl"`
-				Value         struct {
-					Normal   string `yaml:"normal"`
-					Fallback string `yaml:"fallback"`
-				} `yaml:"value"`
-			} `yaml:"record"`
-
-			Cloudfront struct {
-				DistributionId string `yaml:"distribution-id"`
-				Origin         struct {
-					Normal   string `yaml:"normal"`
-					Fallback string `yaml:"fallback"`
-				} `yaml:"origin"`
-			} `yaml:"cloudfront"`
-
-			EC2 struct {
-				AutoScalingGroup struct {
-					Name         string        `yaml:"name"`
-					ShutdownTime time.Duration `yaml:"shutdown-time"`
-				} `yaml:"auto-scaling-group"`
-			} `yaml:"ec2"`
-		} `yaml:"isp-fallback-updater"`
+		ISPFallbackUpdater *ISPFallbackUpdater `yaml:"isp-fallback-updater"`
 	} `yaml:"application"`
 
+	Notifications Notifications `yaml:"notifications"`
+	Audit         Audit         `yaml:"audit"`
+	Uptime        Uptime        `yaml:"uptime"`
+	Election      Election      `yaml:"election"`
+
+	Agent struct {
+		Listening string    `yaml:"listening"`
+		Tls       ServerTls `yaml:"tls"`
+		Tokens    []string  `yaml:"tokens"`
+		Probes    []Probe   `yaml:"probes"`
+	} `yaml:"agent"`
+
 	Aws struct {
 		Credentials struct {
 			AccessKey string `yaml:"access-key"`
 			SecretKey string `yaml:"secret-key"`
 		} `yaml:"credentials"`
 
-		Region string `yaml:"region"`
+		Region      string    `yaml:"region"`
+		Proxy       Proxy     `yaml:"proxy"`
+		LogRequests bool      `yaml:"log-requests"`
+		Retry       AwsRetry  `yaml:"retry"`
+		Events      AwsEvents `yaml:"events"`
+		Metrics     Metrics   `yaml:"metrics"`
 	} `yaml:"aws"`
 
 	Log struct {
-		Level   string        `yaml:"level"`
-		Format  format.Format `yaml:"format"`
-		Colored bool          `yaml:"colored"`
+		Level     string        `yaml:"level"`
+		Format    format.Format `yaml:"format"`
+		Colored   bool          `yaml:"colored"`
+		Sinks     []sink.Sink   `yaml:"sinks"`
+		Redaction struct {
+			Headers       []string `yaml:"headers"`
+			Fields        []string `yaml:"fields"`
+			MaxBodyLength int      `yaml:"max-body-length"`
+		} `yaml:"redaction"`
 	} `yaml:"log"`
 }
 
 var ApplicationConfig Config
 
+// LEGACY_FALLBACK_KEYS were read directly under isp-fallback-updater before the fallback groups
+var LEGACY_FALLBACK_KEYS = []string{"check-interval", "port-fetcher", "record", "cloudfront", "ec2", "actions"}
+
 func LoadConfig(ctx *context.Context) error {
 	loadProfile(ctx)
 
@@ -97,7 +405,13 @@ func LoadConfig(ctx *context.Context) error {
 	}
 
 	logConfig := ApplicationConfig.Log
-	log.ReconfigureLogger(ctx, logConfig.Format, logConfig.Level, logConfig.Colored)
+	err = log.ReconfigureLogger(ctx, logConfig.Format, logConfig.Level, logConfig.Colored, logConfig.Sinks)
+	if err != nil {
+		return errors.New("Failed to configure logger: " + err.Error())
+	}
+
+	redaction := logConfig.Redaction
+	log.SetRedaction(redaction.Headers, redaction.Fields, redaction.MaxBodyLength)
 
 	return nil
 }
@@ -126,12 +440,58 @@ func loadLocalConfig(ctx *context.Context) error {
 		return errors.New("Failed to read configuration file: " + err.Error())
 	}
 
-	err = yaml.Unmarshal(data, &ApplicationConfig)
+	err = decodeConfig(data, &ApplicationConfig)
 	if err != nil {
-		return errors.New("Failed to parse configuration file: " + err.Error())
+		return err
 	}
 
 	log.Info(ctx).Msg("Loaded local config")
 
 	return nil
 }
+
+func decodeConfig(data []byte, target *Config) error {
+	err := checkLegacyKeys(data)
+	if err != nil {
+		return err
+	}
+
+	// unknown keys are rejected, so keys of older versions are not silently ignored
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(target)
+	if err != nil && err != io.EOF {
+		return errors.New("Failed to parse configuration file: " + err.Error())
+	}
+
+	return nil
+}
+
+// checkLegacyKeys points configurations of the single fallback layout to the groups replacing it
+func checkLegacyKeys(data []byte) error {
+	var legacy struct {
+		Application struct {
+			ISPFallbackUpdater map[string]yaml.Node `yaml:"isp-fallback-updater"`
+		} `yaml:"application"`
+	}
+
+	err := yaml.Unmarshal(data, &legacy)
+	if err != nil {
+		return errors.New("Failed to parse configuration file: " + err.Error())
+	}
+
+	var found []string
+	for _, key := range LEGACY_FALLBACK_KEYS {
+		if _, exists := legacy.Application.ISPFallbackUpdater[key]; exists {
+			found = append(found, key)
+		}
+	}
+
+	if len(found) > 0 {
+		return fmt.Errorf("Legacy keys %s of application.isp-fallback-updater are no longer supported, "+
+			"move them into a group of application.isp-fallback-updater.groups as described in README.md", strings.Join(found, ", "))
+	}
+
+	return nil
+}
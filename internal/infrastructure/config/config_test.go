@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"single fallback layout", `
+application:
+  isp-fallback-updater:
+    check-interval: 10s
+    port-fetcher:
+      url: https://another.example.com/status
+    ec2:
+      auto-scaling-group:
+        name: asg-name
+`, "Legacy keys check-interval, port-fetcher, ec2"},
+		{"legacy actions next to groups", `
+application:
+  isp-fallback-updater:
+    groups:
+      - name: home
+    actions:
+      enable: []
+`, "Legacy keys actions"},
+		{"groups layout", `
+application:
+  isp-fallback-updater:
+    groups:
+      - name: home
+        check-interval: 10s
+`, ""},
+		{"unknown key", `
+application:
+  isp-fallback-updater:
+    groups:
+      - name: home
+        port-fetcher:
+          url: https://another.example.com/status
+`, "field port-fetcher not found"},
+		{"fallback disabled", "application:\n  isp-fallback-updater:\n", ""},
+		{"empty file", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var config Config
+			err := decodeConfig([]byte(test.data), &config)
+
+			if test.expected == "" && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)) {
+				t.Fatalf("error %v, expected %q", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeSampleConfig(t *testing.T) {
+	data, err := os.ReadFile("../../../conf/application.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var config Config
+	if err := decodeConfig(data, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Application.ISPFallbackUpdater == nil || len(config.Application.ISPFallbackUpdater.Groups) == 0 {
+		t.Error("sample fallback groups not loaded")
+	}
+}
@@ -0,0 +1,17 @@
+package event
+
+type Type string
+
+const (
+	IP_CHANGED          Type = "ip-changed"
+	DNS_UPDATE_FAILED   Type = "dns-update-failed"
+	FALLBACK_ENABLED    Type = "fallback-enabled"
+	FALLBACK_DISABLED   Type = "fallback-disabled"
+	FALLBACK_FAILED     Type = "fallback-failed"
+	FALLBACK_SUPPRESSED Type = "fallback-suppressed"
+	ASG_SCHEDULED       Type = "asg-scheduled"
+	ASG_SHUT_DOWN       Type = "asg-shut-down"
+	ASG_SCALED          Type = "asg-scaled"
+	ASG_CANCELLED       Type = "asg-cancelled"
+	ACTION_FAILED       Type = "action-failed"
+)
@@ -0,0 +1,8 @@
+package lease
+
+type Type string
+
+const (
+	ROUTE53 Type = "route53"
+	FILE    Type = "file"
+)
@@ -0,0 +1,34 @@
+package probe
+
+type Type string
+
+const (
+	HTTP   Type = "http"
+	TCP    Type = "tcp"
+	TLS    Type = "tls"
+	DNS    Type = "dns"
+	REMOTE Type = "remote"
+)
+
+type Mode string
+
+const (
+	ALL Mode = "all"
+	ANY Mode = "any"
+)
+
+type Class string
+
+const (
+	SUCCESS             Class = "success"
+	DNS_FAILURE         Class = "dns-failure"
+	CONNECT_TIMEOUT     Class = "connect-timeout"
+	CONNECTION_REFUSED  Class = "connection-refused"
+	UNREACHABLE         Class = "unreachable"
+	TLS_FAILURE         Class = "tls-failure"
+	HTTP_ERROR          Class = "http-error"
+	UNEXPECTED_RESPONSE Class = "unexpected-response"
+	NETWORK_ERROR       Class = "network-error"
+)
+
+var DEFAULT_DOWN_CLASSES = []Class{CONNECT_TIMEOUT, CONNECTION_REFUSED, UNREACHABLE}
@@ -0,0 +1,8 @@
+package quiet
+
+type Mode string
+
+const (
+	SUPPRESS Mode = "suppress"
+	NOTIFY   Mode = "notify"
+)
@@ -0,0 +1,8 @@
+package role
+
+type Type string
+
+const (
+	READ_ONLY Type = "read-only"
+	ADMIN     Type = "admin"
+)
@@ -0,0 +1,38 @@
+package sink
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/format"
+	"time"
+)
+
+type Type string
+
+const (
+	STDOUT Type = "stdout"
+	FILE   Type = "file"
+	SYSLOG Type = "syslog"
+)
+
+type Sink struct {
+	Type    Type          `yaml:"type"`
+	Level   string        `yaml:"level"`
+	Format  format.Format `yaml:"format"`
+	Colored bool          `yaml:"colored"`
+	File    File          `yaml:"file"`
+	Syslog  Syslog        `yaml:"syslog"`
+}
+
+type File struct {
+	Path       string        `yaml:"path"`
+	MaxSize    int64         `yaml:"max-size"`
+	Interval   time.Duration `yaml:"interval"`
+	MaxAge     time.Duration `yaml:"max-age"`
+	MaxBackups int           `yaml:"max-backups"`
+}
+
+type Syslog struct {
+	Network  string `yaml:"network"`
+	Address  string `yaml:"address"`
+	Tag      string `yaml:"tag"`
+	Facility string `yaml:"facility"`
+}
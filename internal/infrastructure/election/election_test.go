@@ -0,0 +1,151 @@
+package election
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/lease"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileBackendAcquire(t *testing.T) {
+	ctx := context.Background()
+	backend := NewFileBackend(filepath.Join(t.TempDir(), "leader.json"))
+
+	first, err := backend.Acquire(&ctx, "a", time.Hour)
+	if err != nil || first.Holder != "a" {
+		t.Fatalf("a did not acquire the free lease: %v %v", first, err)
+	}
+
+	current, err := backend.Acquire(&ctx, "b", time.Hour)
+	if err != nil || current.Holder != "a" {
+		t.Fatalf("b took a valid lease: %v %v", current, err)
+	}
+
+	renewed, err := backend.Acquire(&ctx, "a", time.Hour)
+	if err != nil || renewed.Holder != "a" || renewed.ExpiresAt.Before(first.ExpiresAt) {
+		t.Fatalf("a did not renew its lease: %v %v", renewed, err)
+	}
+}
+
+func TestFileBackendExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	backend := NewFileBackend(filepath.Join(t.TempDir(), "leader.json"))
+
+	_, err := backend.Acquire(&ctx, "a", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	current, err := backend.Acquire(&ctx, "b", time.Hour)
+	if err != nil || current.Holder != "b" {
+		t.Fatalf("b did not take the expired lease: %v %v", current, err)
+	}
+}
+
+func TestTxtRoundTrip(t *testing.T) {
+	expected := &Lease{Holder: "home-1", ExpiresAt: time.Unix(1700000000, 0)}
+
+	decoded, err := decodeTxt(encodeTxt(expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Holder != expected.Holder || !decoded.ExpiresAt.Equal(expected.ExpiresAt) {
+		t.Errorf("decoded %v, expected %v", decoded, expected)
+	}
+}
+
+func TestDecodeTxt(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"quoted", `"holder=a;expires=1700000000"`, true},
+		{"unquoted", `holder=a;expires=1700000000`, true},
+		{"missing holder", `"expires=1700000000"`, false},
+		{"missing expiry", `"holder=a"`, false},
+		{"invalid expiry", `"holder=a;expires=soon"`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decodeTxt(test.value)
+			if (err == nil) != test.valid {
+				t.Errorf("decodeTxt(%s) valid = %t, expected %t", test.value, err == nil, test.valid)
+			}
+		})
+	}
+}
+
+func TestNewElectorValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.Election
+		valid  bool
+	}{
+		{"disabled", config.Election{}, true},
+		{"defaults", config.Election{Enabled: true, Backend: lease.FILE}, true},
+		{"renew beyond skew margin", config.Election{Enabled: true, Backend: lease.FILE, Ttl: 10 * time.Second, RenewInterval: 6 * time.Second}, false},
+		{"custom skew", config.Election{Enabled: true, Backend: lease.FILE, Ttl: 10 * time.Second, RenewInterval: 6 * time.Second, MaxClockSkew: time.Second}, true},
+		{"route53 without record", config.Election{Enabled: true, Backend: lease.ROUTE53}, false},
+		{"unknown backend", config.Election{Enabled: true, Backend: "etcd"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewElector(test.config)
+			if (err == nil) != test.valid {
+				t.Errorf("NewElector valid = %t, expected %t (error %v)", err == nil, test.valid, err)
+			}
+		})
+	}
+}
+
+func TestElectorLeadershipAndSkewMargin(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leader.json")
+	electionConfig := config.Election{
+		Enabled:       true,
+		Backend:       lease.FILE,
+		Ttl:           time.Hour,
+		RenewInterval: time.Minute,
+		MaxClockSkew:  10 * time.Minute,
+		File:          config.ElectionFile{Path: path},
+	}
+
+	electionConfig.Id = "a"
+	leader, err := NewElector(electionConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	electionConfig.Id = "b"
+	follower, err := NewElector(electionConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leader.backend = NewFileBackend(path)
+	follower.backend = NewFileBackend(path)
+
+	before := time.Now()
+	leader.renew(&ctx)
+	follower.renew(&ctx)
+
+	if !leader.IsLeader() || follower.IsLeader() {
+		t.Fatalf("leader %t, follower %t", leader.IsLeader(), follower.IsLeader())
+	}
+
+	if follower.Leader() != "a" {
+		t.Errorf("follower sees leader %s", follower.Leader())
+	}
+
+	if limit := before.Add(50 * time.Minute); leader.validUntil.After(limit.Add(time.Second)) {
+		t.Errorf("valid until %s, expected the skew margin before %s", leader.validUntil, limit)
+	}
+}
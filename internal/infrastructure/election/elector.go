@@ -0,0 +1,235 @@
+package election
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/lease"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	DEFAULT_TTL            = 30 * time.Second
+	DEFAULT_MAX_CLOCK_SKEW = 5 * time.Second
+	DEFAULT_FILE_PATH      = "logs/leader.json"
+	RENEW_DIVISOR          = 3
+)
+
+type AwsConfigProvider func() (*aws.Config, error)
+
+type Lease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Backend stores the lease, Acquire must take it only when free, expired or already held by the
+// holder, atomically against other instances, and return the lease in force after the attempt
+type Backend interface {
+	Name() string
+	Acquire(ctx *context.Context, holder string, ttl time.Duration) (*Lease, error)
+}
+
+type Status struct {
+	Enabled   bool       `json:"enabled"`
+	Id        string     `json:"id"`
+	Backend   string     `json:"backend,omitempty"`
+	Leader    bool       `json:"leader"`
+	Holder    string     `json:"holder,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+}
+
+// Elector keeps this instance as leader while it holds the lease. The lease expiry is an absolute time
+// written by the holder and compared by the others with their own clocks, so the clocks of the instances
+// must not drift apart more than max-clock-skew: the leader stops acting max-clock-skew before the expiry
+// it wrote, which is the earliest a follower with a clock ahead by that much can take over.
+// Scheduled capacities live in the memory of the leader, the new leader applies the actions of the current
+// fallback state again, which schedules them anew with their full delay
+type Elector struct {
+	config     config.Election
+	backend    Backend
+	leader     bool
+	validUntil time.Time
+	lease      *Lease
+	lastError  string
+	mutex      sync.RWMutex
+}
+
+func NewElector(electionConfig config.Election) (*Elector, error) {
+	if utils.IsEmptyStr(electionConfig.Id) {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, errors.New("Error on resolving election id: " + err.Error())
+		}
+
+		electionConfig.Id = hostname
+	}
+
+	if !electionConfig.Enabled {
+		return &Elector{
+			config: electionConfig,
+		}, nil
+	}
+
+	if electionConfig.Ttl <= 0 {
+		electionConfig.Ttl = DEFAULT_TTL
+	}
+
+	if electionConfig.RenewInterval <= 0 {
+		electionConfig.RenewInterval = electionConfig.Ttl / RENEW_DIVISOR
+	}
+
+	if electionConfig.MaxClockSkew <= 0 {
+		electionConfig.MaxClockSkew = DEFAULT_MAX_CLOCK_SKEW
+	}
+
+	if electionConfig.RenewInterval >= electionConfig.Ttl-electionConfig.MaxClockSkew {
+		return nil, errors.New("Election renew-interval must be lower than ttl minus max-clock-skew")
+	}
+
+	switch electionConfig.Backend {
+	case lease.ROUTE53:
+		route53Config := electionConfig.Route53
+		if utils.IsEmptyStr(route53Config.HostedZoneId) || utils.IsEmptyStr(route53Config.RecordName) {
+			return nil, errors.New("Route 53 election requires hosted-zone-id and record-name")
+		}
+
+	case lease.FILE:
+		if utils.IsEmptyStr(electionConfig.File.Path) {
+			electionConfig.File.Path = DEFAULT_FILE_PATH
+		}
+
+	default:
+		return nil, fmt.Errorf("Invalid election backend: %s", electionConfig.Backend)
+	}
+
+	return &Elector{
+		config: electionConfig,
+	}, nil
+}
+
+func newBackend(electionConfig config.Election, awsConfigProvider AwsConfigProvider) Backend {
+	if electionConfig.Backend == lease.ROUTE53 {
+		return NewRoute53Backend(electionConfig.Route53, awsConfigProvider)
+	}
+
+	return NewFileBackend(electionConfig.File.Path)
+}
+
+// Start runs the first election synchronously, so the schedulers start knowing the leadership
+func (elector *Elector) Start(ctx *context.Context, awsConfigProvider AwsConfigProvider) {
+	if !elector.config.Enabled {
+		return
+	}
+
+	elector.backend = newBackend(elector.config, awsConfigProvider)
+
+	log.Info(ctx).Msg(fmt.Sprintf("Starting leader election of %s on %s with ttl %s", elector.config.Id, elector.backend.Name(), elector.config.Ttl))
+
+	elector.renew(ctx)
+
+	go func() {
+		ticker := time.NewTicker(elector.config.RenewInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			elector.renew(ctx)
+		}
+	}()
+}
+
+// IsLeader is always true without election, otherwise the lease must still be valid locally
+func (elector *Elector) IsLeader() bool {
+	if !elector.config.Enabled {
+		return true
+	}
+
+	elector.mutex.RLock()
+	defer elector.mutex.RUnlock()
+
+	return elector.leader && time.Now().Before(elector.validUntil)
+}
+
+func (elector *Elector) Leader() string {
+	if !elector.config.Enabled {
+		return elector.config.Id
+	}
+
+	elector.mutex.RLock()
+	defer elector.mutex.RUnlock()
+
+	if elector.lease == nil {
+		return ""
+	}
+
+	return elector.lease.Holder
+}
+
+func (elector *Elector) Status() Status {
+	status := Status{
+		Enabled: elector.config.Enabled,
+		Id:      elector.config.Id,
+		Leader:  elector.IsLeader(),
+	}
+
+	if !elector.config.Enabled {
+		return status
+	}
+
+	elector.mutex.RLock()
+	defer elector.mutex.RUnlock()
+
+	status.Backend = string(elector.config.Backend)
+	status.LastError = elector.lastError
+
+	if elector.lease != nil {
+		expiresAt := elector.lease.ExpiresAt
+		status.Holder = elector.lease.Holder
+		status.ExpiresAt = &expiresAt
+	}
+
+	return status
+}
+
+func (elector *Elector) renew(ctx *context.Context) {
+	wasLeader := elector.IsLeader()
+
+	// the local validity starts before the call, so a slow backend never extends it
+	attemptTime := time.Now()
+	current, err := elector.backend.Acquire(ctx, elector.config.Id, elector.config.Ttl)
+
+	elector.mutex.Lock()
+
+	if err != nil {
+		elector.lastError = err.Error()
+	} else {
+		elector.lastError = ""
+		elector.lease = current
+		elector.leader = current != nil && current.Holder == elector.config.Id
+		if elector.leader {
+			elector.validUntil = attemptTime.Add(elector.config.Ttl - elector.config.MaxClockSkew)
+		}
+	}
+
+	elector.mutex.Unlock()
+
+	if err != nil {
+		log.Error(ctx).Msg("Error on renewing leader lease: " + err.Error())
+	}
+
+	isLeader := elector.IsLeader()
+	if isLeader && !wasLeader {
+		log.Info(ctx).Msg(fmt.Sprintf("Instance %s became the leader", elector.config.Id))
+	} else if !isLeader && wasLeader {
+		log.Warn(ctx).Msg(fmt.Sprintf("Instance %s lost the leadership to %s", elector.config.Id, elector.Leader()))
+	} else if !isLeader && current != nil {
+		log.Debug(ctx).Msg(fmt.Sprintf("Instance %s is a follower of %s", elector.config.Id, current.Holder))
+	}
+}
@@ -0,0 +1,126 @@
+package election
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	LOCK_SUFFIX      = ".lock"
+	TEMP_SUFFIX      = ".tmp"
+	LOCK_RETRIES     = 50
+	LOCK_RETRY_DELAY = 20 * time.Millisecond
+	STALE_LOCK_AGE   = 10 * time.Second
+)
+
+// FileBackend keeps the lease in a local JSON file, meant for tests and instances sharing a volume
+type FileBackend struct {
+	path string
+}
+
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{
+		path: path,
+	}
+}
+
+func (backend *FileBackend) Name() string {
+	return "file " + backend.path
+}
+
+func (backend *FileBackend) Acquire(ctx *context.Context, holder string, ttl time.Duration) (*Lease, error) {
+	err := os.MkdirAll(filepath.Dir(backend.path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := backend.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	current, err := backend.read()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if current != nil && current.Holder != holder && now.Before(current.ExpiresAt) {
+		return current, nil
+	}
+
+	next := &Lease{
+		Holder:    holder,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	data, err := json.Marshal(next)
+	if err != nil {
+		return nil, err
+	}
+
+	tempPath := backend.path + TEMP_SUFFIX
+	err = os.WriteFile(tempPath, data, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Rename(tempPath, backend.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+func (backend *FileBackend) read() (*Lease, error) {
+	data, err := os.ReadFile(backend.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var current Lease
+	err = json.Unmarshal(data, &current)
+	if err != nil {
+		// an unreadable lease is treated as expired and replaced
+		return nil, nil
+	}
+
+	return &current, nil
+}
+
+// lock creates the lock file exclusively, a lock left behind by a crashed instance is removed once stale
+func (backend *FileBackend) lock() (func(), error) {
+	lockPath := backend.path + LOCK_SUFFIX
+
+	for retry := 0; retry < LOCK_RETRIES; retry++ {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		info, err := os.Stat(lockPath)
+		if err == nil && time.Since(info.ModTime()) > STALE_LOCK_AGE {
+			os.Remove(lockPath)
+			continue
+		}
+
+		time.Sleep(LOCK_RETRY_DELAY)
+	}
+
+	return nil, errors.New("Timeout on locking lease file " + lockPath)
+}
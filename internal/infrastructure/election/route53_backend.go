@@ -0,0 +1,175 @@
+package election
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	ROUTE53_RECORD_TTL      = 10
+	ROUTE53_HOLDER_FIELD    = "holder="
+	ROUTE53_EXPIRES_FIELD   = "expires="
+	ROUTE53_FIELD_SEPARATOR = ";"
+	INVALID_CHANGE_BATCH    = "InvalidChangeBatch"
+)
+
+// Route53Backend keeps the lease in a TXT record, the value is replaced with a DELETE of the
+// exact value read plus a CREATE in one change batch, so a concurrent writer makes it fail
+type Route53Backend struct {
+	config            config.ElectionRoute53
+	awsConfigProvider AwsConfigProvider
+}
+
+func NewRoute53Backend(route53Config config.ElectionRoute53, awsConfigProvider AwsConfigProvider) *Route53Backend {
+	return &Route53Backend{
+		config:            route53Config,
+		awsConfigProvider: awsConfigProvider,
+	}
+}
+
+func (backend *Route53Backend) Name() string {
+	return "route53 " + backend.config.RecordName
+}
+
+func (backend *Route53Backend) Acquire(ctx *context.Context, holder string, ttl time.Duration) (*Lease, error) {
+	awsConfig, err := backend.awsConfigProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	client := route53.NewFromConfig(*awsConfig)
+
+	current, record, err := backend.read(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if current != nil && current.Holder != holder && now.Before(current.ExpiresAt) {
+		return current, nil
+	}
+
+	next := &Lease{
+		Holder:    holder,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	var changes []route53types.Change
+	if record != nil {
+		changes = append(changes, route53types.Change{
+			Action:            route53types.ChangeActionDelete,
+			ResourceRecordSet: record,
+		})
+	}
+
+	changes = append(changes, route53types.Change{
+		Action: route53types.ChangeActionCreate,
+		ResourceRecordSet: &route53types.ResourceRecordSet{
+			Name: aws.String(backend.config.RecordName),
+			Type: route53types.RRTypeTxt,
+			TTL:  aws.Int64(ROUTE53_RECORD_TTL),
+			ResourceRecords: []route53types.ResourceRecord{
+				{Value: aws.String(encodeTxt(next))},
+			},
+		},
+	})
+
+	_, err = client.ChangeResourceRecordSets(*ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(backend.config.HostedZoneId),
+		ChangeBatch: &route53types.ChangeBatch{
+			Comment: aws.String("Leader lease of " + holder),
+			Changes: changes,
+		},
+	})
+
+	if err != nil {
+		var apiError smithy.APIError
+		if errors.As(err, &apiError) && apiError.ErrorCode() == INVALID_CHANGE_BATCH {
+			// another instance changed the record between the read and the write
+			current, _, err = backend.read(ctx, client)
+			return current, err
+		}
+
+		return nil, err
+	}
+
+	return next, nil
+}
+
+func (backend *Route53Backend) read(ctx *context.Context, client *route53.Client) (*Lease, *route53types.ResourceRecordSet, error) {
+	output, err := client.ListResourceRecordSets(*ctx, &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(backend.config.HostedZoneId),
+		StartRecordName: aws.String(backend.config.RecordName),
+		StartRecordType: route53types.RRTypeTxt,
+		MaxItems:        aws.Int32(1),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(output.ResourceRecordSets) == 0 {
+		return nil, nil, nil
+	}
+
+	record := output.ResourceRecordSets[0]
+	if record.Type != route53types.RRTypeTxt || normalizeName(aws.ToString(record.Name)) != normalizeName(backend.config.RecordName) {
+		return nil, nil, nil
+	}
+
+	if len(record.ResourceRecords) == 0 {
+		return nil, &record, nil
+	}
+
+	current, err := decodeTxt(aws.ToString(record.ResourceRecords[0].Value))
+	if err != nil {
+		// an unreadable lease is treated as expired and replaced
+		return nil, &record, nil
+	}
+
+	return current, &record, nil
+}
+
+func encodeTxt(current *Lease) string {
+	return strconv.Quote(ROUTE53_HOLDER_FIELD + current.Holder + ROUTE53_FIELD_SEPARATOR + ROUTE53_EXPIRES_FIELD + strconv.FormatInt(current.ExpiresAt.Unix(), 10))
+}
+
+func decodeTxt(value string) (*Lease, error) {
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		unquoted = strings.Trim(value, `"`)
+	}
+
+	current := &Lease{}
+	for _, field := range strings.Split(unquoted, ROUTE53_FIELD_SEPARATOR) {
+		if strings.HasPrefix(field, ROUTE53_HOLDER_FIELD) {
+			current.Holder = strings.TrimPrefix(field, ROUTE53_HOLDER_FIELD)
+		} else if strings.HasPrefix(field, ROUTE53_EXPIRES_FIELD) {
+			expires, err := strconv.ParseInt(strings.TrimPrefix(field, ROUTE53_EXPIRES_FIELD), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			current.ExpiresAt = time.Unix(expires, 0)
+		}
+	}
+
+	if current.Holder == "" || current.ExpiresAt.IsZero() {
+		return nil, fmt.Errorf("Invalid lease record: %s", value)
+	}
+
+	return current, nil
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
@@ -0,0 +1,251 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+const (
+	DEFAULT_NAMESPACE      = "AwsInfrastructureHelper"
+	DEFAULT_FLUSH_INTERVAL = time.Minute
+	DEFAULT_BATCH_SIZE     = 500
+	MAX_BATCH_SIZE         = 1000 // datums accepted by one PutMetricData call
+	DEFAULT_MAX_BUFFER     = 10000
+	MAX_DIMENSIONS         = 30 // dimensions accepted on one data point
+	FINAL_FLUSH_TIMEOUT    = 10 * time.Second
+
+	PROBE_SUCCESS   = "ProbeSuccess"
+	PROBE_LATENCY   = "ProbeLatency"
+	ISP_DOWN        = "IspDown"
+	FALLBACK_STATE  = "FallbackState"
+	IP_CHANGE_COUNT = "IpChangeCount"
+
+	GROUP_DIMENSION = "Group"
+	PROBE_DIMENSION = "Probe"
+	LINK_DIMENSION  = "Link"
+
+	BUILT_IN_DIMENSIONS = 2 // group and probe of the probe metrics
+)
+
+type AwsConfigProvider func() (*aws.Config, error)
+
+type Recorder struct {
+	config     config.Metrics
+	dimensions []types.Dimension
+	buffer     []types.MetricDatum
+	mutex      sync.Mutex
+}
+
+func NewRecorder(metricsConfig config.Metrics) (*Recorder, error) {
+	if utils.IsEmptyStr(metricsConfig.Namespace) {
+		metricsConfig.Namespace = DEFAULT_NAMESPACE
+	}
+
+	if metricsConfig.FlushInterval <= 0 {
+		metricsConfig.FlushInterval = DEFAULT_FLUSH_INTERVAL
+	}
+
+	if metricsConfig.BatchSize <= 0 {
+		metricsConfig.BatchSize = DEFAULT_BATCH_SIZE
+	}
+
+	metricsConfig.BatchSize = min(metricsConfig.BatchSize, MAX_BATCH_SIZE)
+
+	if metricsConfig.MaxBuffer <= 0 {
+		metricsConfig.MaxBuffer = DEFAULT_MAX_BUFFER
+	}
+
+	if len(metricsConfig.Dimensions)+BUILT_IN_DIMENSIONS > MAX_DIMENSIONS {
+		return nil, fmt.Errorf("Metrics accept at most %d dimensions besides %s and %s", MAX_DIMENSIONS-BUILT_IN_DIMENSIONS, GROUP_DIMENSION, PROBE_DIMENSION)
+	}
+
+	names := make([]string, 0, len(metricsConfig.Dimensions))
+	for name, value := range metricsConfig.Dimensions {
+		if name == GROUP_DIMENSION || name == PROBE_DIMENSION || name == LINK_DIMENSION {
+			return nil, errors.New("Metric dimension name is reserved: " + name)
+		}
+
+		if utils.IsEmptyStr(name) || utils.IsEmptyStr(value) {
+			return nil, fmt.Errorf("Metric dimension %q has no name or value", name)
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var dimensions []types.Dimension
+	for _, name := range names {
+		dimensions = append(dimensions, types.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(metricsConfig.Dimensions[name]),
+		})
+	}
+
+	return &Recorder{
+		config:     metricsConfig,
+		dimensions: dimensions,
+	}, nil
+}
+
+func (recorder *Recorder) Start(ctx *context.Context, awsConfigProvider AwsConfigProvider) {
+	if !recorder.config.Enabled {
+		return
+	}
+
+	log.Info(ctx).Msg(fmt.Sprintf("Publishing CloudWatch metrics to namespace %s every %s", recorder.config.Namespace, recorder.config.FlushInterval))
+
+	go func() {
+		ticker := time.NewTicker(recorder.config.FlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-(*ctx).Done():
+				recorder.finalFlush(ctx, awsConfigProvider)
+				log.Info(ctx).Msg("Stopping CloudWatch metrics")
+				return
+
+			case <-ticker.C:
+				recorder.flush(ctx, awsConfigProvider)
+			}
+		}
+	}()
+}
+
+// finalFlush publishes the buffered data points once more, outliving the cancelled context for a while
+func (recorder *Recorder) finalFlush(ctx *context.Context, awsConfigProvider AwsConfigProvider) {
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(*ctx), FINAL_FLUSH_TIMEOUT)
+	defer cancel()
+
+	recorder.flush(&flushCtx, awsConfigProvider)
+}
+
+func (recorder *Recorder) RecordProbe(group string, probe string, success bool, latency time.Duration) {
+	dimensions := []types.Dimension{dimension(GROUP_DIMENSION, group), dimension(PROBE_DIMENSION, probe)}
+
+	recorder.add(PROBE_SUCCESS, boolValue(success), types.StandardUnitCount, dimensions)
+	recorder.add(PROBE_LATENCY, float64(latency.Milliseconds()), types.StandardUnitMilliseconds, dimensions)
+}
+
+func (recorder *Recorder) RecordGroup(group string, down bool, fallback bool) {
+	dimensions := []types.Dimension{dimension(GROUP_DIMENSION, group)}
+
+	recorder.add(ISP_DOWN, boolValue(down), types.StandardUnitCount, dimensions)
+	recorder.add(FALLBACK_STATE, boolValue(fallback), types.StandardUnitCount, dimensions)
+}
+
+func (recorder *Recorder) RecordIpChange(link string) {
+	recorder.add(IP_CHANGE_COUNT, 1, types.StandardUnitCount, []types.Dimension{dimension(LINK_DIMENSION, link)})
+}
+
+func (recorder *Recorder) add(name string, value float64, unit types.StandardUnit, dimensions []types.Dimension) {
+	if !recorder.config.Enabled {
+		return
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.buffer = append(recorder.buffer, types.MetricDatum{
+		MetricName: aws.String(name),
+		Value:      aws.Float64(value),
+		Unit:       unit,
+		Timestamp:  aws.Time(time.Now()),
+		Dimensions: append(append([]types.Dimension{}, recorder.dimensions...), dimensions...),
+	})
+
+	// keep the newest data points when CloudWatch is unreachable for a long time
+	if overflow := len(recorder.buffer) - recorder.config.MaxBuffer; overflow > 0 {
+		recorder.buffer = recorder.buffer[overflow:]
+	}
+}
+
+func (recorder *Recorder) drain() []types.MetricDatum {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	buffer := recorder.buffer
+	recorder.buffer = nil
+
+	return buffer
+}
+
+func (recorder *Recorder) requeue(data []types.MetricDatum) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.buffer = append(data, recorder.buffer...)
+	if overflow := len(recorder.buffer) - recorder.config.MaxBuffer; overflow > 0 {
+		recorder.buffer = recorder.buffer[overflow:]
+	}
+}
+
+func (recorder *Recorder) flush(ctx *context.Context, awsConfigProvider AwsConfigProvider) {
+	data := recorder.drain()
+	if len(data) == 0 {
+		return
+	}
+
+	awsConfig, err := awsConfigProvider()
+	if err != nil {
+		log.Error(ctx).Msg("Error on getting AWS config for metrics: " + err.Error())
+		recorder.requeue(data)
+		return
+	}
+
+	client := cloudwatch.NewFromConfig(*awsConfig)
+	batchSize := recorder.config.BatchSize
+	published := len(data)
+
+	for start := 0; start < len(data); start += batchSize {
+		end := min(start+batchSize, len(data))
+
+		_, err := client.PutMetricData(*ctx, &cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(recorder.config.Namespace),
+			MetricData: data[start:end],
+		})
+		if err != nil {
+			// a rejected batch fails the same way on every flush, only transient errors keep the data points
+			code := exceptions.Classify(err)
+			if !code.IsRetryable() {
+				log.Error(ctx).Msg(fmt.Sprintf("Dropping %d CloudWatch metrics rejected with %s: %s", end-start, code, err.Error()))
+				published -= end - start
+				continue
+			}
+
+			log.Error(ctx).Msg(fmt.Sprintf("Error on publishing %d CloudWatch metrics: %s", len(data)-start, err.Error()))
+			recorder.requeue(data[start:])
+			return
+		}
+	}
+
+	log.Debug(ctx).Msg(fmt.Sprintf("Published %d CloudWatch metrics", published))
+}
+
+func dimension(name string, value string) types.Dimension {
+	return types.Dimension{
+		Name:  aws.String(name),
+		Value: aws.String(value),
+	}
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
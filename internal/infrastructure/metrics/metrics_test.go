@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const (
+	PUT_METRIC_DATA_RESPONSE = `<PutMetricDataResponse><ResponseMetadata><RequestId>id</RequestId></ResponseMetadata></PutMetricDataResponse>`
+	ERROR_RESPONSE           = `<ErrorResponse><Error><Type>Sender</Type><Code>%s</Code><Message>failed</Message></Error><RequestId>id</RequestId></ErrorResponse>`
+)
+
+// cloudWatch answers each PutMetricData call with the next status and error code
+type cloudWatch struct {
+	replies []reply
+	calls   int
+	mutex   sync.Mutex
+}
+
+type reply struct {
+	status int
+	code   string
+}
+
+func newCloudWatch(t *testing.T, replies ...reply) (*cloudWatch, AwsConfigProvider) {
+	cloudWatch := &cloudWatch{replies: replies}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		cloudWatch.mutex.Lock()
+		reply := reply{http.StatusOK, ""}
+		if cloudWatch.calls < len(cloudWatch.replies) {
+			reply = cloudWatch.replies[cloudWatch.calls]
+		}
+		cloudWatch.calls++
+		cloudWatch.mutex.Unlock()
+
+		writer.Header().Set("Content-Type", "text/xml")
+		writer.WriteHeader(reply.status)
+
+		if reply.status == http.StatusOK {
+			fmt.Fprint(writer, PUT_METRIC_DATA_RESPONSE)
+		} else {
+			fmt.Fprintf(writer, ERROR_RESPONSE, reply.code)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	awsConfig := aws.Config{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		BaseEndpoint:     aws.String(server.URL),
+		RetryMaxAttempts: 1,
+	}
+
+	return cloudWatch, func() (*aws.Config, error) { return &awsConfig, nil }
+}
+
+func (cloudWatch *cloudWatch) callCount() int {
+	cloudWatch.mutex.Lock()
+	defer cloudWatch.mutex.Unlock()
+
+	return cloudWatch.calls
+}
+
+func newTestRecorder(t *testing.T, metricsConfig config.Metrics) *Recorder {
+	metricsConfig.Enabled = true
+
+	recorder, err := NewRecorder(metricsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return recorder
+}
+
+func (recorder *Recorder) buffered() int {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return len(recorder.buffer)
+}
+
+func TestNewRecorderValidation(t *testing.T) {
+	tooMany := map[string]string{}
+	for index := 0; index < MAX_DIMENSIONS-BUILT_IN_DIMENSIONS+1; index++ {
+		tooMany[fmt.Sprintf("Dimension%d", index)] = "value"
+	}
+
+	allowed := map[string]string{}
+	for index := 0; index < MAX_DIMENSIONS-BUILT_IN_DIMENSIONS; index++ {
+		allowed[fmt.Sprintf("Dimension%d", index)] = "value"
+	}
+
+	tests := []struct {
+		name       string
+		dimensions map[string]string
+		valid      bool
+	}{
+		{"none", nil, true},
+		{"custom", map[string]string{"Site": "home"}, true},
+		{"most allowed", allowed, true},
+		{"too many", tooMany, false},
+		{"group", map[string]string{GROUP_DIMENSION: "home"}, false},
+		{"probe", map[string]string{PROBE_DIMENSION: "https"}, false},
+		{"link", map[string]string{LINK_DIMENSION: "isp-a"}, false},
+		{"empty value", map[string]string{"Site": ""}, false},
+		{"empty name", map[string]string{"": "home"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewRecorder(config.Metrics{Dimensions: test.dimensions})
+			if (err == nil) != test.valid {
+				t.Errorf("error %v, expected valid %t", err, test.valid)
+			}
+		})
+	}
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name     string
+		replies  []reply
+		calls    int
+		buffered int
+	}{
+		{"published", nil, 2, 0},
+		{"rejected batch dropped", []reply{{http.StatusBadRequest, "InvalidParameterValue"}}, 2, 0},
+		{"every rejected batch dropped", []reply{{http.StatusBadRequest, "InvalidParameterValue"}, {http.StatusBadRequest, "MissingParameter"}}, 2, 0},
+		{"transient error keeps the data", []reply{{http.StatusServiceUnavailable, "ServiceUnavailable"}}, 1, 3},
+		{"transient error keeps the remaining batches", []reply{{http.StatusOK, ""}, {http.StatusInternalServerError, "InternalServiceError"}}, 2, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cloudWatch, awsConfigProvider := newCloudWatch(t, test.replies...)
+			recorder := newTestRecorder(t, config.Metrics{BatchSize: 2})
+
+			recorder.RecordGroup("home", true, false)
+			recorder.RecordIpChange("isp-a")
+
+			ctx := context.Background()
+			recorder.flush(&ctx, awsConfigProvider)
+
+			if cloudWatch.callCount() != test.calls || recorder.buffered() != test.buffered {
+				t.Errorf("calls %d, buffered %d, expected %d and %d", cloudWatch.callCount(), recorder.buffered(), test.calls, test.buffered)
+			}
+		})
+	}
+}
+
+func TestStartFlushesWhenStopped(t *testing.T) {
+	cloudWatch, awsConfigProvider := newCloudWatch(t)
+	recorder := newTestRecorder(t, config.Metrics{FlushInterval: time.Hour})
+	recorder.RecordIpChange("isp-a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	recorder.Start(&ctx, awsConfigProvider)
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for cloudWatch.callCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if recorder.buffered() != 0 || cloudWatch.callCount() != 1 {
+		t.Errorf("buffered %d after %d calls, expected the data points published when stopped", recorder.buffered(), cloudWatch.callCount())
+	}
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package network
+
+import (
+	"net"
+	"syscall"
+)
+
+func bindInterface(dialer *Dialer, interfaceName string) error {
+	_, err := net.InterfaceByName(interfaceName)
+	if err != nil {
+		return err
+	}
+
+	dialer.control = func(network string, address string, conn syscall.RawConn) error {
+		var bindErr error
+
+		err := conn.Control(func(fd uintptr) {
+			bindErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, interfaceName)
+		})
+		if err != nil {
+			return err
+		}
+
+		return bindErr
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build !linux
+
+package network
+
+import (
+	"errors"
+	"net"
+)
+
+func bindInterface(dialer *Dialer, interfaceName string) error {
+	if dialer.sourceIp != nil {
+		return nil
+	}
+
+	sourceIp, err := interfaceAddress(interfaceName)
+	if err != nil {
+		return err
+	}
+
+	dialer.sourceIp = sourceIp
+	return nil
+}
+
+func interfaceAddress(interfaceName string) (net.IP, error) {
+	networkInterface, err := net.InterfaceByName(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := networkInterface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, address := range addresses {
+		if ipNet, ok := address.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+	}
+
+	return nil, errors.New("No IPv4 address found on interface " + interfaceName)
+}
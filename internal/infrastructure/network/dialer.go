@@ -0,0 +1,69 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type Dialer struct {
+	timeout   time.Duration
+	keepAlive time.Duration
+	sourceIp  net.IP
+	control   func(network string, address string, conn syscall.RawConn) error
+}
+
+func NewDialer(bind config.Bind, timeout time.Duration) (*Dialer, error) {
+	dialer := &Dialer{
+		timeout: timeout,
+	}
+
+	if utils.IsNotEmptyStr(bind.SourceAddress) {
+		sourceIp := net.ParseIP(bind.SourceAddress)
+		if sourceIp == nil {
+			return nil, errors.New("Invalid source address: " + bind.SourceAddress)
+		}
+
+		dialer.sourceIp = sourceIp
+	}
+
+	if utils.IsNotEmptyStr(bind.Interface) {
+		err := bindInterface(dialer, bind.Interface)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dialer, nil
+}
+
+func (dialer *Dialer) SetKeepAlive(keepAlive time.Duration) {
+	dialer.keepAlive = keepAlive
+}
+
+func (dialer *Dialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	return dialer.NetDialer(network).DialContext(ctx, network, address)
+}
+
+func (dialer *Dialer) NetDialer(network string) *net.Dialer {
+	netDialer := &net.Dialer{
+		Timeout:   dialer.timeout,
+		KeepAlive: dialer.keepAlive,
+		Control:   dialer.control,
+	}
+
+	if dialer.sourceIp != nil {
+		if strings.HasPrefix(network, "udp") {
+			netDialer.LocalAddr = &net.UDPAddr{IP: dialer.sourceIp}
+		} else {
+			netDialer.LocalAddr = &net.TCPAddr{IP: dialer.sourceIp}
+		}
+	}
+
+	return netDialer
+}
@@ -0,0 +1,47 @@
+package network
+
+import (
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+type ProxyFunc func(request *http.Request) (*url.URL, error)
+
+// socks5 already resolves names on the proxy, net/http of Go 1.21 does not accept socks5h
+var supportedProxySchemes = []string{"http", "https", "socks5"}
+
+func NewProxyFunc(proxyConfig config.Proxy) (ProxyFunc, error) {
+	if utils.IsEmptyStr(proxyConfig.Url) {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyUrl, err := url.Parse(proxyConfig.Url)
+	if err != nil {
+		return nil, errors.New("Invalid proxy url: " + err.Error())
+	}
+
+	if !slices.Contains(supportedProxySchemes, proxyUrl.Scheme) {
+		return nil, errors.New("Unsupported proxy scheme: " + proxyUrl.Scheme)
+	}
+
+	if utils.IsNotEmptyStr(proxyConfig.Username) {
+		proxyUrl.User = url.UserPassword(proxyConfig.Username, proxyConfig.Password)
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyUrl.String(),
+		HTTPSProxy: proxyUrl.String(),
+		NoProxy:    strings.Join(proxyConfig.NoProxy, ","),
+	}).ProxyFunc()
+
+	return func(request *http.Request) (*url.URL, error) {
+		return proxyFunc(request.URL)
+	}, nil
+}
@@ -0,0 +1,85 @@
+package network
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+var ErrFingerprintMismatch = errors.New("certificate fingerprint mismatch")
+
+func NewTlsConfig(tlsConfig config.TlsClient) (*tls.Config, error) {
+	clientTlsConfig := &tls.Config{
+		ServerName:         tlsConfig.ServerName,
+		InsecureSkipVerify: tlsConfig.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if utils.IsNotEmptyStr(tlsConfig.CaFile) {
+		caBundle, err := os.ReadFile(tlsConfig.CaFile)
+		if err != nil {
+			return nil, errors.New("Failed to read CA bundle: " + err.Error())
+		}
+
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, errors.New("No certificates found in CA bundle " + tlsConfig.CaFile)
+		}
+
+		clientTlsConfig.RootCAs = rootCAs
+	}
+
+	if utils.IsNotEmptyStr(tlsConfig.CertFile) || utils.IsNotEmptyStr(tlsConfig.KeyFile) {
+		certificate, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+		if err != nil {
+			return nil, errors.New("Failed to load client certificate: " + err.Error())
+		}
+
+		clientTlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if len(tlsConfig.PinnedFingerprints) > 0 {
+		var fingerprints []string
+		for _, fingerprint := range tlsConfig.PinnedFingerprints {
+			fingerprints = append(fingerprints, normalizeFingerprint(fingerprint))
+		}
+
+		clientTlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPinnedFingerprint(state, fingerprints)
+		}
+	}
+
+	return clientTlsConfig, nil
+}
+
+func Fingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func verifyPinnedFingerprint(state tls.ConnectionState, fingerprints []string) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("No peer certificate presented")
+	}
+
+	fingerprint := Fingerprint(state.PeerCertificates[constants.ZERO])
+	if !slices.Contains(fingerprints, fingerprint) {
+		return fmt.Errorf("%w: %s does not match any pinned fingerprint", ErrFingerprintMismatch, fingerprint)
+	}
+
+	return nil
+}
+
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.ReplaceAll(fingerprint, constants.COLON, constants.EMPTY)
+	return strings.ToLower(strings.TrimSpace(fingerprint))
+}
@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/entity"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/channel"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const DEFAULT_TELEGRAM_URL = "https://api.telegram.org"
+
+type httpChannel struct {
+	config       config.NotificationChannel
+	bodyTemplate *template.Template
+	webhookApi   *api.WebhookApi
+}
+
+func newHttpChannel(channelConfig config.NotificationChannel, webhookApi *api.WebhookApi) (*httpChannel, error) {
+	if channelConfig.Type == channel.TELEGRAM {
+		if utils.IsEmptyStr(channelConfig.Token) || utils.IsEmptyStr(channelConfig.ChatId) {
+			return nil, errors.New("Telegram channel requires token and chat-id: " + channelConfig.Name)
+		}
+	} else if utils.IsEmptyStr(channelConfig.Url) {
+		return nil, errors.New("Notification channel requires an url: " + channelConfig.Name)
+	}
+
+	_, err := webhookApi.Client(channelConfig.Timeout, channelConfig.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Notification channel %s: %w", channelConfig.Name, err)
+	}
+
+	httpChannel := &httpChannel{
+		config:     channelConfig,
+		webhookApi: webhookApi,
+	}
+
+	if utils.IsNotEmptyStr(channelConfig.Body) {
+		bodyTemplate, err := template.New(channelConfig.Name).Funcs(template.FuncMap{
+			"json": toJson,
+		}).Parse(channelConfig.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		httpChannel.bodyTemplate = bodyTemplate
+	}
+
+	return httpChannel, nil
+}
+
+func (httpChannel *httpChannel) Name() string {
+	return httpChannel.config.Name
+}
+
+func (httpChannel *httpChannel) Send(ctx *context.Context, event entity.Event) error {
+	channelConfig := httpChannel.config
+	method := channelConfig.Method
+	requestUrl := channelConfig.Url
+
+	headers := make(map[string]string)
+	for key, value := range channelConfig.Headers {
+		headers[key] = value
+	}
+
+	var body string
+	var err error
+
+	switch channelConfig.Type {
+	case channel.SLACK:
+		body, err = toJson(map[string]string{"text": "*" + event.Title() + "*\n" + event.Message})
+
+	case channel.DISCORD:
+		body, err = toJson(map[string]string{"content": "**" + event.Title() + "**\n" + event.Message})
+
+	case channel.TELEGRAM:
+		if utils.IsEmptyStr(requestUrl) {
+			requestUrl = DEFAULT_TELEGRAM_URL
+		}
+
+		requestUrl = strings.TrimSuffix(requestUrl, "/") + "/bot" + channelConfig.Token + "/sendMessage"
+		body, err = toJson(map[string]string{
+			"chat_id": channelConfig.ChatId,
+			"text":    event.Title() + "\n" + event.Message,
+		})
+
+	case channel.NTFY:
+		body = event.Message
+		headers["Content-Type"] = "text/plain"
+		headers["Title"] = event.Title()
+
+		if channelConfig.Priority > 0 {
+			headers["Priority"] = strconv.Itoa(channelConfig.Priority)
+		}
+
+		if utils.IsNotEmptyStr(channelConfig.Token) {
+			headers["Authorization"] = "Bearer " + channelConfig.Token
+		}
+
+	case channel.GOTIFY:
+		requestUrl = strings.TrimSuffix(requestUrl, "/") + "/message"
+		headers["X-Gotify-Key"] = channelConfig.Token
+		body, err = toJson(map[string]any{
+			"title":    event.Title(),
+			"message":  event.Message,
+			"priority": channelConfig.Priority,
+		})
+
+	default:
+		body, err = httpChannel.renderBody(event)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if utils.IsEmptyStr(method) {
+		method = http.MethodPost
+	}
+
+	erra := httpChannel.webhookApi.Call(ctx, method, requestUrl, channelConfig.Timeout, channelConfig.Client, headers, body)
+	if erra != nil {
+		return erra
+	}
+
+	return nil
+}
+
+func (httpChannel *httpChannel) renderBody(event entity.Event) (string, error) {
+	if httpChannel.bodyTemplate == nil {
+		return toJson(event)
+	}
+
+	var buffer bytes.Buffer
+	err := httpChannel.bodyTemplate.Execute(&buffer, event)
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func toJson(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,153 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/entity"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/channel"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Channel interface {
+	Name() string
+	Send(ctx *context.Context, event entity.Event) error
+}
+
+type Notifier struct {
+	channels  map[string]Channel
+	rules     []config.NotificationRule
+	lastSent  map[string]time.Time
+	lastSeen  map[string]time.Time
+	retention time.Duration
+	lastPrune time.Time
+	mutex     sync.Mutex
+}
+
+func NewNotifier(notifications config.Notifications, webhookApi *api.WebhookApi) (*Notifier, error) {
+	channels := make(map[string]Channel)
+	for _, channelConfig := range notifications.Channels {
+		if _, exists := channels[channelConfig.Name]; exists {
+			return nil, errors.New("Duplicated notification channel: " + channelConfig.Name)
+		}
+
+		notificationChannel, err := newChannel(channelConfig, webhookApi)
+		if err != nil {
+			return nil, err
+		}
+
+		channels[channelConfig.Name] = notificationChannel
+	}
+
+	var retention time.Duration
+	for _, rule := range notifications.Rules {
+		retention = max(retention, rule.DedupWindow, rule.RateLimit)
+
+		for _, channelName := range rule.Channels {
+			if _, exists := channels[channelName]; !exists {
+				return nil, errors.New("Unknown notification channel on rule: " + channelName)
+			}
+		}
+	}
+
+	return &Notifier{
+		channels:  channels,
+		rules:     notifications.Rules,
+		lastSent:  make(map[string]time.Time),
+		lastSeen:  make(map[string]time.Time),
+		retention: retention,
+	}, nil
+}
+
+func newChannel(channelConfig config.NotificationChannel, webhookApi *api.WebhookApi) (Channel, error) {
+	switch channelConfig.Type {
+	case channel.WEBHOOK, channel.SLACK, channel.DISCORD, channel.TELEGRAM, channel.NTFY, channel.GOTIFY:
+		return newHttpChannel(channelConfig, webhookApi)
+
+	case channel.SMTP:
+		return newSmtpChannel(channelConfig)
+	}
+
+	return nil, errors.New("Unknown notification channel type: " + string(channelConfig.Type))
+}
+
+// Notify sends the event in background to every channel of the matching rules
+func (notifier *Notifier) Notify(ctx *context.Context, event entity.Event) {
+	for index, rule := range notifier.rules {
+		if len(rule.Events) > 0 && !slices.Contains(rule.Events, event.Type) {
+			continue
+		}
+
+		for _, channelName := range rule.Channels {
+			if !notifier.allow(index, channelName, rule, event) {
+				log.Debug(ctx).Msg(fmt.Sprintf("Skipping notification %s to %s", event.Type, channelName))
+				continue
+			}
+
+			notificationChannel := notifier.channels[channelName]
+			go notifier.send(ctx, notificationChannel, event)
+		}
+	}
+}
+
+func (notifier *Notifier) send(ctx *context.Context, notificationChannel Channel, event entity.Event) {
+	log.Info(ctx).Msg(fmt.Sprintf("Sending notification %s to %s", event.Type, notificationChannel.Name()))
+
+	err := notificationChannel.Send(ctx, event)
+	if err != nil {
+		log.Error(ctx).Msg(fmt.Sprintf("Error on sending notification to %s: %s", notificationChannel.Name(), err.Error()))
+	}
+}
+
+func (notifier *Notifier) allow(ruleIndex int, channelName string, rule config.NotificationRule, event entity.Event) bool {
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	now := event.Time
+	notifier.prune(now)
+
+	rateKey := fmt.Sprintf("%d|%s|%s", ruleIndex, channelName, event.Type)
+	dedupKey := fmt.Sprintf("%d|%s|%s", ruleIndex, channelName, event.Key())
+
+	if rule.DedupWindow > 0 {
+		lastSeen, exists := notifier.lastSeen[dedupKey]
+		if exists && now.Sub(lastSeen) < rule.DedupWindow {
+			return false
+		}
+	}
+
+	if rule.RateLimit > 0 {
+		lastSent, exists := notifier.lastSent[rateKey]
+		if exists && now.Sub(lastSent) < rule.RateLimit {
+			return false
+		}
+	}
+
+	notifier.lastSeen[dedupKey] = now
+	notifier.lastSent[rateKey] = now
+
+	return true
+}
+
+// prune evicts the entries older than the longest window, the dedup keys carry the event message
+// so they would otherwise grow for the life of the process. It expects the caller to hold the lock
+func (notifier *Notifier) prune(now time.Time) {
+	if now.Sub(notifier.lastPrune) < notifier.retention {
+		return
+	}
+
+	notifier.lastPrune = now
+
+	for _, entries := range []map[string]time.Time{notifier.lastSeen, notifier.lastSent} {
+		for key, last := range entries {
+			if now.Sub(last) >= notifier.retention {
+				delete(entries, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/core/entity"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/event"
+	"testing"
+	"time"
+)
+
+func newTestNotifier(rule config.NotificationRule) *Notifier {
+	notifier, err := NewNotifier(config.Notifications{Rules: []config.NotificationRule{rule}}, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return notifier
+}
+
+func newTestEvent(eventType event.Type, message string, at time.Time) entity.Event {
+	testEvent := entity.NewEvent(eventType, message)
+	testEvent.Time = at
+
+	return testEvent
+}
+
+func TestAllow(t *testing.T) {
+	base := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule     config.NotificationRule
+		first    entity.Event
+		second   entity.Event
+		expected bool
+	}{
+		{"no limits", config.NotificationRule{},
+			newTestEvent(event.IP_CHANGED, "a", base), newTestEvent(event.IP_CHANGED, "a", base), true},
+		{"duplicate inside window", config.NotificationRule{DedupWindow: time.Minute},
+			newTestEvent(event.IP_CHANGED, "a", base), newTestEvent(event.IP_CHANGED, "a", base.Add(30*time.Second)), false},
+		{"duplicate after window", config.NotificationRule{DedupWindow: time.Minute},
+			newTestEvent(event.IP_CHANGED, "a", base), newTestEvent(event.IP_CHANGED, "a", base.Add(time.Minute)), true},
+		{"other message inside window", config.NotificationRule{DedupWindow: time.Minute},
+			newTestEvent(event.IP_CHANGED, "a", base), newTestEvent(event.IP_CHANGED, "b", base.Add(time.Second)), true},
+		{"rate limited type", config.NotificationRule{RateLimit: time.Minute},
+			newTestEvent(event.IP_CHANGED, "a", base), newTestEvent(event.IP_CHANGED, "b", base.Add(time.Second)), false},
+		{"rate limit per type", config.NotificationRule{RateLimit: time.Minute},
+			newTestEvent(event.IP_CHANGED, "a", base), newTestEvent(event.FALLBACK_ENABLED, "a", base.Add(time.Second)), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notifier := newTestNotifier(test.rule)
+
+			if !notifier.allow(0, "channel", test.rule, test.first) {
+				t.Fatal("first event not allowed")
+			}
+
+			if allowed := notifier.allow(0, "channel", test.rule, test.second); allowed != test.expected {
+				t.Errorf("second event allowed = %t, expected %t", allowed, test.expected)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	rule := config.NotificationRule{DedupWindow: time.Minute, RateLimit: 10 * time.Second}
+	notifier := newTestNotifier(rule)
+	base := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for index, message := range []string{"a", "b", "c"} {
+		notifier.allow(0, "channel", rule, newTestEvent(event.IP_CHANGED, message, base.Add(time.Duration(index)*20*time.Second)))
+	}
+
+	if len(notifier.lastSeen) != 3 {
+		t.Fatalf("expected 3 dedup entries, got %d", len(notifier.lastSeen))
+	}
+
+	notifier.allow(0, "channel", rule, newTestEvent(event.IP_CHANGED, "d", base.Add(5*time.Minute)))
+
+	if len(notifier.lastSeen) != 1 || len(notifier.lastSent) != 1 {
+		t.Errorf("expected expired entries evicted, got %d dedup and %d rate entries", len(notifier.lastSeen), len(notifier.lastSent))
+	}
+}
@@ -0,0 +1,154 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/entity"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_SMTP_PORT    = 587
+	DEFAULT_SMTP_TIMEOUT = 30 * time.Second
+)
+
+type smtpChannel struct {
+	name    string
+	config  config.SmtpChannel
+	timeout time.Duration
+}
+
+func newSmtpChannel(channelConfig config.NotificationChannel) (*smtpChannel, error) {
+	smtpConfig := channelConfig.Smtp
+
+	if utils.IsEmptyStr(smtpConfig.Host) || utils.IsEmptyStr(smtpConfig.From) || len(smtpConfig.To) == 0 {
+		return nil, errors.New("SMTP channel requires host, from and to: " + channelConfig.Name)
+	}
+
+	if smtpConfig.Port == 0 {
+		smtpConfig.Port = DEFAULT_SMTP_PORT
+	}
+
+	timeout := channelConfig.Timeout
+	if timeout <= 0 {
+		timeout = DEFAULT_SMTP_TIMEOUT
+	}
+
+	return &smtpChannel{
+		name:    channelConfig.Name,
+		config:  smtpConfig,
+		timeout: timeout,
+	}, nil
+}
+
+func (smtpChannel *smtpChannel) Name() string {
+	return smtpChannel.name
+}
+
+// Send follows smtp.SendMail, using STARTTLS whenever the server offers it, but bounds the whole
+// conversation by the channel timeout so an unreachable server does not hang the sender
+func (smtpChannel *smtpChannel) Send(ctx *context.Context, event entity.Event) error {
+	smtpConfig := smtpChannel.config
+	address := net.JoinHostPort(smtpConfig.Host, strconv.Itoa(smtpConfig.Port))
+
+	dialer := net.Dialer{Timeout: smtpChannel.timeout}
+	conn, err := dialer.DialContext(*ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+
+	err = conn.SetDeadline(time.Now().Add(smtpChannel.timeout))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, smtpConfig.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: smtpConfig.Host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if utils.IsNotEmptyStr(smtpConfig.Username) {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("SMTP server does not support AUTH")
+		}
+
+		err = client.Auth(smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(smtpConfig.From)
+	if err != nil {
+		return err
+	}
+
+	for _, to := range smtpConfig.To {
+		err = client.Rcpt(to)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(smtpChannel.message(event))
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (smtpChannel *smtpChannel) message(event entity.Event) []byte {
+	smtpConfig := smtpChannel.config
+
+	var builder strings.Builder
+	builder.WriteString("From: " + smtpConfig.From + "\r\n")
+	builder.WriteString("To: " + strings.Join(smtpConfig.To, ", ") + "\r\n")
+	builder.WriteString("Subject: " + event.Title() + "\r\n")
+	builder.WriteString("Date: " + event.Time.Format(time.RFC1123Z) + "\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(event.Message + "\r\n")
+
+	keys := make([]string, 0, len(event.Details))
+	for key := range event.Details {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf("%s: %s\r\n", key, event.Details[key]))
+	}
+
+	return []byte(builder.String())
+}
@@ -0,0 +1,107 @@
+package prober
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/network"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+var unreachableErrnos = []syscall.Errno{
+	syscall.EHOSTUNREACH,
+	syscall.ENETUNREACH,
+	syscall.EHOSTDOWN,
+	syscall.ENETDOWN,
+	syscall.ECONNRESET,
+}
+
+func Classify(err error) probe.Class {
+	if err == nil {
+		return probe.SUCCESS
+	}
+
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		if dnsError.IsTimeout && !dnsError.IsNotFound {
+			return probe.CONNECT_TIMEOUT
+		}
+
+		return probe.DNS_FAILURE
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return probe.CONNECTION_REFUSED
+	}
+
+	if isUnreachableError(err) {
+		return probe.UNREACHABLE
+	}
+
+	if isTlsError(err) {
+		return probe.TLS_FAILURE
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return probe.CONNECT_TIMEOUT
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return probe.CONNECT_TIMEOUT
+	}
+
+	return probe.NETWORK_ERROR
+}
+
+// isUnreachableError matches the errors of a lost uplink, when the route or the link to the target is gone
+func isUnreachableError(err error) bool {
+	for _, errno := range unreachableErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+
+	return strings.Contains(err.Error(), "no route to host") || strings.Contains(err.Error(), "network is unreachable")
+}
+
+func isTlsError(err error) bool {
+	var recordHeaderError tls.RecordHeaderError
+	var alertError tls.AlertError
+	var verificationError *tls.CertificateVerificationError
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+
+	return errors.Is(err, network.ErrFingerprintMismatch) || errors.As(err, &recordHeaderError) || errors.As(err, &alertError) ||
+		errors.As(err, &verificationError) || errors.As(err, &unknownAuthorityError) ||
+		errors.As(err, &hostnameError) || errors.As(err, &certificateInvalidError)
+}
+
+func ErrorCode(class probe.Class) exceptions.Code {
+	switch class {
+	case probe.SUCCESS:
+		return ""
+	case probe.CONNECT_TIMEOUT:
+		return exceptions.PROBE_TIMEOUT
+	case probe.DNS_FAILURE, probe.CONNECTION_REFUSED, probe.UNREACHABLE, probe.NETWORK_ERROR:
+		return exceptions.NETWORK
+	}
+
+	return exceptions.PROBE_FAILED
+}
+
+func IsDownClass(class probe.Class, downClasses []probe.Class) bool {
+	if len(downClasses) == 0 {
+		downClasses = probe.DEFAULT_DOWN_CLASSES
+	}
+
+	return slices.Contains(downClasses, class)
+}
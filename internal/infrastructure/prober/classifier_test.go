@@ -0,0 +1,92 @@
+package prober
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/network"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func dialError(errno syscall.Errno) error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: errno}}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected probe.Class
+	}{
+		{"nil", nil, probe.SUCCESS},
+		{"dns not found", &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}, probe.DNS_FAILURE},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}, probe.CONNECT_TIMEOUT},
+		{"connection refused", dialError(syscall.ECONNREFUSED), probe.CONNECTION_REFUSED},
+		{"host unreachable", dialError(syscall.EHOSTUNREACH), probe.UNREACHABLE},
+		{"network unreachable", dialError(syscall.ENETUNREACH), probe.UNREACHABLE},
+		{"connection reset", &url.Error{Op: "Get", URL: "https://example.com", Err: dialError(syscall.ECONNRESET)}, probe.UNREACHABLE},
+		{"no route to host message", errors.New("dial tcp 10.0.0.1:443: connect: no route to host"), probe.UNREACHABLE},
+		{"deadline exceeded", fmt.Errorf("probe: %w", context.DeadlineExceeded), probe.CONNECT_TIMEOUT},
+		{"io deadline", &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, probe.CONNECT_TIMEOUT},
+		{"unknown authority", &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}, probe.TLS_FAILURE},
+		{"fingerprint mismatch", fmt.Errorf("handshake: %w", network.ErrFingerprintMismatch), probe.TLS_FAILURE},
+		{"other", errors.New("unexpected EOF"), probe.NETWORK_ERROR},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if class := Classify(test.err); class != test.expected {
+				t.Errorf("Classify(%v) = %s, expected %s", test.err, class, test.expected)
+			}
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		class    probe.Class
+		expected exceptions.Code
+	}{
+		{probe.SUCCESS, ""},
+		{probe.CONNECT_TIMEOUT, exceptions.PROBE_TIMEOUT},
+		{probe.UNREACHABLE, exceptions.NETWORK},
+		{probe.NETWORK_ERROR, exceptions.NETWORK},
+		{probe.HTTP_ERROR, exceptions.PROBE_FAILED},
+	}
+
+	for _, test := range tests {
+		if code := ErrorCode(test.class); code != test.expected {
+			t.Errorf("ErrorCode(%s) = %s, expected %s", test.class, code, test.expected)
+		}
+	}
+}
+
+func TestIsDownClass(t *testing.T) {
+	tests := []struct {
+		name        string
+		class       probe.Class
+		downClasses []probe.Class
+		expected    bool
+	}{
+		{"default timeout", probe.CONNECT_TIMEOUT, nil, true},
+		{"default unreachable", probe.UNREACHABLE, nil, true},
+		{"default http error", probe.HTTP_ERROR, nil, false},
+		{"configured http error", probe.HTTP_ERROR, []probe.Class{probe.HTTP_ERROR}, true},
+		{"configured without timeout", probe.CONNECT_TIMEOUT, []probe.Class{probe.HTTP_ERROR}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if down := IsDownClass(test.class, test.downClasses); down != test.expected {
+				t.Errorf("IsDownClass(%s) = %t, expected %t", test.class, down, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package prober
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	probetype "fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/network"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	RECORD_TYPE_A     = "A"
+	RECORD_TYPE_AAAA  = "AAAA"
+	RECORD_TYPE_CNAME = "CNAME"
+	RECORD_TYPE_TXT   = "TXT"
+)
+
+type DnsProbe struct {
+	config   config.Probe
+	resolver *net.Resolver
+}
+
+func newDnsProbe(probeConfig config.Probe) (*DnsProbe, error) {
+	dnsConfig := probeConfig.Dns
+	if utils.IsEmptyStr(dnsConfig.Name) {
+		return nil, errors.New("DNS probe " + probeConfig.Name + " requires a name")
+	}
+
+	resolver := net.DefaultResolver
+	bind := probeConfig.Bind
+
+	if utils.IsNotEmptyStr(dnsConfig.Server) || utils.IsNotEmptyStr(bind.SourceAddress) || utils.IsNotEmptyStr(bind.Interface) {
+		dialer, err := network.NewDialer(bind, probeConfig.Timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, networkName string, address string) (net.Conn, error) {
+				if utils.IsNotEmptyStr(dnsConfig.Server) {
+					address = dnsConfig.Server
+				}
+
+				return dialer.DialContext(ctx, networkName, address)
+			},
+		}
+	}
+
+	return &DnsProbe{
+		config:   probeConfig,
+		resolver: resolver,
+	}, nil
+}
+
+func (probe *DnsProbe) Name() string {
+	return probe.config.Name
+}
+
+func (probe *DnsProbe) Check(ctx *context.Context) Result {
+	dnsConfig := probe.config.Dns
+	start := time.Now()
+
+	timeoutCtx, cancel := context.WithTimeout(*ctx, probe.config.Timeout)
+	defer cancel()
+
+	answers, err := probe.lookup(timeoutCtx, dnsConfig.Name, strings.ToUpper(dnsConfig.RecordType))
+	if err != nil {
+		return newErrorResult(probe.Name(), start, err)
+	}
+
+	if len(answers) == 0 {
+		return newResult(probe.Name(), start, probetype.DNS_FAILURE, "No answers for "+dnsConfig.Name)
+	}
+
+	if len(dnsConfig.Expected) > 0 {
+		for _, answer := range answers {
+			if slices.Contains(dnsConfig.Expected, answer) {
+				return newResult(probe.Name(), start, probetype.SUCCESS, "Resolved to "+answer)
+			}
+		}
+
+		return newResult(probe.Name(), start, probetype.UNEXPECTED_RESPONSE, fmt.Sprintf("Unexpected answers %v", answers))
+	}
+
+	return newResult(probe.Name(), start, probetype.SUCCESS, fmt.Sprintf("Resolved to %v", answers))
+}
+
+func (probe *DnsProbe) lookup(ctx context.Context, name string, recordType string) ([]string, error) {
+	switch recordType {
+	case RECORD_TYPE_CNAME:
+		cname, err := probe.resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{strings.TrimSuffix(cname, ".")}, nil
+
+	case RECORD_TYPE_TXT:
+		return probe.resolver.LookupTXT(ctx, name)
+	}
+
+	network := "ip"
+	if recordType == RECORD_TYPE_A {
+		network = "ip4"
+	} else if recordType == RECORD_TYPE_AAAA {
+		network = "ip6"
+	}
+
+	ips, err := probe.resolver.LookupIP(ctx, network, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var answers []string
+	for _, ip := range ips {
+		answers = append(answers, ip.String())
+	}
+
+	return answers, nil
+}
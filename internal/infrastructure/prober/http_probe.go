@@ -0,0 +1,83 @@
+package prober
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	probetype "fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+)
+
+type HttpProbe struct {
+	config     config.Probe
+	fetcherApi *api.FetcherApi
+	httpClient *api.HttpClient
+	bodyMatch  *regexp.Regexp
+}
+
+func newHttpProbe(probeConfig config.Probe, fetcherApi *api.FetcherApi) (*HttpProbe, error) {
+	if utils.IsEmptyStr(probeConfig.Http.Url) {
+		return nil, errors.New("HTTP probe " + probeConfig.Name + " requires an url")
+	}
+
+	httpClient, err := api.NewHttpClient(probeConfig.Timeout, probeConfig.Bind, probeConfig.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	httpProbe := &HttpProbe{
+		config:     probeConfig,
+		fetcherApi: fetcherApi,
+		httpClient: httpClient,
+	}
+
+	if utils.IsNotEmptyStr(probeConfig.Http.BodyMatch) {
+		bodyMatch, err := regexp.Compile(probeConfig.Http.BodyMatch)
+		if err != nil {
+			return nil, errors.New("Invalid body match of HTTP probe " + probeConfig.Name + ": " + err.Error())
+		}
+
+		httpProbe.bodyMatch = bodyMatch
+	}
+
+	return httpProbe, nil
+}
+
+func (probe *HttpProbe) Name() string {
+	return probe.config.Name
+}
+
+func (probe *HttpProbe) Check(ctx *context.Context) Result {
+	httpConfig := probe.config.Http
+	start := time.Now()
+
+	status, body, erra := probe.fetcherApi.Probe(ctx, probe.httpClient, httpConfig.Method, httpConfig.Url, httpConfig.Host)
+	if erra != nil && status == 0 {
+		if erra.Err != nil {
+			return newErrorResult(probe.Name(), start, erra.Err)
+		}
+
+		return newResult(probe.Name(), start, probetype.NETWORK_ERROR, erra.Message)
+	}
+
+	expectedStatus := httpConfig.ExpectedStatus
+	if len(expectedStatus) > 0 && !slices.Contains(expectedStatus, status) {
+		return newResult(probe.Name(), start, probetype.HTTP_ERROR, fmt.Sprintf("Unexpected HTTP status %d", status))
+	}
+
+	if len(expectedStatus) == 0 && status >= http.StatusBadRequest {
+		return newResult(probe.Name(), start, probetype.HTTP_ERROR, fmt.Sprintf("HTTP error status %d", status))
+	}
+
+	if probe.bodyMatch != nil && !probe.bodyMatch.Match(body) {
+		return newResult(probe.Name(), start, probetype.UNEXPECTED_RESPONSE, "Response body does not match "+httpConfig.BodyMatch)
+	}
+
+	return newResult(probe.Name(), start, probetype.SUCCESS, fmt.Sprintf("HTTP status %d", status))
+}
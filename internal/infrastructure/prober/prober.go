@@ -0,0 +1,99 @@
+package prober
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"time"
+)
+
+const DEFAULT_TIMEOUT = 5 * time.Second
+
+type Result struct {
+	Probe   string        `json:"probe"`
+	Success bool          `json:"success"`
+	Class   probe.Class   `json:"class"`
+	Latency time.Duration `json:"latency"`
+	Message string        `json:"message,omitempty"`
+}
+
+type Probe interface {
+	Name() string
+	Check(ctx *context.Context) Result
+}
+
+func NewProbe(probeConfig config.Probe, fetcherApi *api.FetcherApi, agentApi *api.AgentApi) (Probe, error) {
+	if probeConfig.Timeout == 0 {
+		probeConfig.Timeout = DEFAULT_TIMEOUT
+	}
+
+	if utils.IsEmptyStr(probeConfig.Name) {
+		probeConfig.Name = string(probeConfig.Type)
+	}
+
+	switch probeConfig.Type {
+	case probe.HTTP:
+		return newHttpProbe(probeConfig, fetcherApi)
+	case probe.TCP:
+		return newTcpProbe(probeConfig)
+	case probe.TLS:
+		return newTlsProbe(probeConfig)
+	case probe.DNS:
+		return newDnsProbe(probeConfig)
+	case probe.REMOTE:
+		return newRemoteProbe(probeConfig, agentApi)
+	}
+
+	return nil, errors.New("Unknown probe type: " + string(probeConfig.Type))
+}
+
+func NewProbes(probeConfigs []config.Probe, fetcherApi *api.FetcherApi, agentApi *api.AgentApi) ([]Probe, error) {
+	var probes []Probe
+
+	for _, probeConfig := range probeConfigs {
+		probe, err := NewProbe(probeConfig, fetcherApi, agentApi)
+		if err != nil {
+			return nil, err
+		}
+
+		probes = append(probes, probe)
+	}
+
+	return probes, nil
+}
+
+func IsDown(results []Result, mode probe.Mode, downClasses []probe.Class) bool {
+	if len(results) == 0 {
+		return false
+	}
+
+	failures := 0
+	for _, result := range results {
+		if IsDownClass(result.Class, downClasses) {
+			failures++
+		}
+	}
+
+	if mode == probe.ANY {
+		return failures > 0
+	}
+
+	return failures == len(results)
+}
+
+func newResult(name string, start time.Time, class probe.Class, message string) Result {
+	return Result{
+		Probe:   name,
+		Success: class == probe.SUCCESS,
+		Class:   class,
+		Latency: time.Since(start),
+		Message: message,
+	}
+}
+
+func newErrorResult(name string, start time.Time, err error) Result {
+	return newResult(name, start, Classify(err), err.Error())
+}
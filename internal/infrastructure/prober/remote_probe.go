@@ -0,0 +1,116 @@
+package prober
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	probetype "fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RemoteProbe struct {
+	config     config.Probe
+	agentApi   *api.AgentApi
+	httpClient *api.HttpClient
+}
+
+func newRemoteProbe(probeConfig config.Probe, agentApi *api.AgentApi) (*RemoteProbe, error) {
+	remoteConfig := probeConfig.Remote
+	if utils.IsEmptyStr(remoteConfig.Probe) {
+		return nil, errors.New("Remote probe " + probeConfig.Name + " requires the agent probe name")
+	}
+
+	if len(remoteConfig.Agents) == 0 {
+		return nil, errors.New("Remote probe " + probeConfig.Name + " requires at least one agent")
+	}
+
+	httpClient, err := api.NewHttpClient(probeConfig.Timeout, probeConfig.Bind, probeConfig.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoteProbe{
+		config:     probeConfig,
+		agentApi:   agentApi,
+		httpClient: httpClient,
+	}, nil
+}
+
+func (probe *RemoteProbe) Name() string {
+	return probe.config.Name
+}
+
+func (probe *RemoteProbe) Check(ctx *context.Context) Result {
+	remoteConfig := probe.config.Remote
+	start := time.Now()
+
+	agentResults := make([]*Result, len(remoteConfig.Agents))
+	agentErrors := make([]string, len(remoteConfig.Agents))
+
+	var waitGroup sync.WaitGroup
+	for index, agent := range remoteConfig.Agents {
+		waitGroup.Add(1)
+
+		go func(index int, agentUrl string, token string) {
+			defer waitGroup.Done()
+
+			var agentResult Result
+			erra := probe.agentApi.Check(ctx, probe.httpClient, agentUrl, token, remoteConfig.Probe, &agentResult)
+			if erra != nil {
+				message := erra.Message
+				if utils.IsEmptyStr(message) {
+					message = fmt.Sprintf("HTTP status %d", erra.Status)
+				}
+
+				agentErrors[index] = fmt.Sprintf("%s: %s", agentUrl, message)
+				return
+			}
+
+			agentResults[index] = &agentResult
+		}(index, agent.Url, agent.Token)
+	}
+
+	waitGroup.Wait()
+
+	classes := make(map[probetype.Class]int)
+	var messages []string
+
+	for index, agentResult := range agentResults {
+		if agentResult == nil {
+			continue
+		}
+
+		agentUrl := remoteConfig.Agents[index].Url
+		if agentResult.Success {
+			return newResult(probe.Name(), start, probetype.SUCCESS, fmt.Sprintf("Agent %s: %s", agentUrl, agentResult.Message))
+		}
+
+		classes[agentResult.Class]++
+		messages = append(messages, fmt.Sprintf("%s: %s", agentUrl, agentResult.Message))
+	}
+
+	if len(messages) == 0 {
+		return newResult(probe.Name(), start, probetype.NETWORK_ERROR, "No agent available: "+strings.Join(agentErrors, "; "))
+	}
+
+	return newResult(probe.Name(), start, mostFrequentClass(classes), strings.Join(messages, "; "))
+}
+
+func mostFrequentClass(classes map[probetype.Class]int) probetype.Class {
+	var mostFrequent probetype.Class
+	count := 0
+
+	for class, classCount := range classes {
+		if classCount > count || (classCount == count && class < mostFrequent) {
+			mostFrequent = class
+			count = classCount
+		}
+	}
+
+	return mostFrequent
+}
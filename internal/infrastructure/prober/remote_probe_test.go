@@ -0,0 +1,92 @@
+package prober
+
+import (
+	"context"
+	"encoding/json"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type agentReply struct {
+	status int
+	result Result
+}
+
+func newAgent(t *testing.T, reply agentReply) string {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != api.AGENT_CHECK_PATH || request.URL.Query().Get("probe") != "https" || request.Header.Get("Authorization") != "Bearer secret" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(reply.status)
+		json.NewEncoder(writer).Encode(reply.result)
+	}))
+
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRemoteProbeCheck(t *testing.T) {
+	up := agentReply{http.StatusOK, Result{Success: true, Class: probe.SUCCESS, Message: "ok"}}
+	timeout := agentReply{http.StatusOK, Result{Class: probe.CONNECT_TIMEOUT, Message: "timeout"}}
+	refused := agentReply{http.StatusOK, Result{Class: probe.CONNECTION_REFUSED, Message: "refused"}}
+	broken := agentReply{http.StatusInternalServerError, Result{}}
+
+	tests := []struct {
+		name     string
+		agents   []agentReply
+		success  bool
+		expected probe.Class
+	}{
+		{"any agent up", []agentReply{timeout, up}, true, probe.SUCCESS},
+		{"all agents down", []agentReply{timeout, timeout, refused}, false, probe.CONNECT_TIMEOUT},
+		{"tie picks the first class by name", []agentReply{timeout, refused}, false, probe.CONNECT_TIMEOUT},
+		{"unreachable agents ignored", []agentReply{broken, refused}, false, probe.CONNECTION_REFUSED},
+		{"no agent available", []agentReply{broken}, false, probe.NETWORK_ERROR},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			probeConfig := config.Probe{Name: "external", Type: probe.REMOTE, Timeout: 2 * time.Second}
+			probeConfig.Remote.Probe = "https"
+
+			for _, reply := range test.agents {
+				probeConfig.Remote.Agents = append(probeConfig.Remote.Agents, struct {
+					Url   string `yaml:"url"`
+					Token string `yaml:"token"`
+				}{Url: newAgent(t, reply), Token: "secret"})
+			}
+
+			remoteProbe, err := newRemoteProbe(probeConfig, api.NewAgentApi())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			result := remoteProbe.Check(&ctx)
+
+			if result.Success != test.success || result.Class != test.expected {
+				t.Errorf("result %t %s, expected %t %s (%s)", result.Success, result.Class, test.success, test.expected, result.Message)
+			}
+		})
+	}
+}
+
+func TestNewRemoteProbeValidation(t *testing.T) {
+	probeConfig := config.Probe{Name: "external", Type: probe.REMOTE}
+	if _, err := newRemoteProbe(probeConfig, api.NewAgentApi()); err == nil {
+		t.Error("remote probe without agent probe name created")
+	}
+
+	probeConfig.Remote.Probe = "https"
+	if _, err := newRemoteProbe(probeConfig, api.NewAgentApi()); err == nil {
+		t.Error("remote probe without agents created")
+	}
+}
@@ -0,0 +1,49 @@
+package prober
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	probetype "fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/network"
+	"time"
+)
+
+type TcpProbe struct {
+	config config.Probe
+	dialer *network.Dialer
+}
+
+func newTcpProbe(probeConfig config.Probe) (*TcpProbe, error) {
+	if utils.IsEmptyStr(probeConfig.Tcp.Address) {
+		return nil, errors.New("TCP probe " + probeConfig.Name + " requires an address")
+	}
+
+	dialer, err := network.NewDialer(probeConfig.Bind, probeConfig.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TcpProbe{
+		config: probeConfig,
+		dialer: dialer,
+	}, nil
+}
+
+func (probe *TcpProbe) Name() string {
+	return probe.config.Name
+}
+
+func (probe *TcpProbe) Check(ctx *context.Context) Result {
+	start := time.Now()
+
+	connection, err := probe.dialer.DialContext(*ctx, "tcp", probe.config.Tcp.Address)
+	if err != nil {
+		return newErrorResult(probe.Name(), start, err)
+	}
+
+	defer connection.Close()
+
+	return newResult(probe.Name(), start, probetype.SUCCESS, "Connected to "+probe.config.Tcp.Address)
+}
@@ -0,0 +1,92 @@
+package prober
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	probetype "fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/probe"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/network"
+	"fmt"
+	"net"
+	"time"
+)
+
+type TlsProbe struct {
+	config    config.Probe
+	dialer    *network.Dialer
+	tlsConfig *tls.Config
+}
+
+func newTlsProbe(probeConfig config.Probe) (*TlsProbe, error) {
+	if utils.IsEmptyStr(probeConfig.Tls.Address) {
+		return nil, errors.New("TLS probe " + probeConfig.Name + " requires an address")
+	}
+
+	dialer, err := network.NewDialer(probeConfig.Bind, probeConfig.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := network.NewTlsConfig(probeConfig.Client.Tls)
+	if err != nil {
+		return nil, err
+	}
+
+	if utils.IsNotEmptyStr(probeConfig.Tls.ServerName) {
+		tlsConfig.ServerName = probeConfig.Tls.ServerName
+	}
+
+	if probeConfig.Tls.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return &TlsProbe{
+		config:    probeConfig,
+		dialer:    dialer,
+		tlsConfig: tlsConfig,
+	}, nil
+}
+
+func (probe *TlsProbe) Name() string {
+	return probe.config.Name
+}
+
+func (probe *TlsProbe) Check(ctx *context.Context) Result {
+	tlsConfig := probe.config.Tls
+	start := time.Now()
+
+	dialTlsConfig := probe.tlsConfig.Clone()
+	if utils.IsEmptyStr(dialTlsConfig.ServerName) {
+		host, _, err := net.SplitHostPort(tlsConfig.Address)
+		if err == nil {
+			dialTlsConfig.ServerName = host
+		}
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: probe.dialer.NetDialer("tcp"),
+		Config:    dialTlsConfig,
+	}
+
+	connection, err := dialer.DialContext(*ctx, "tcp", tlsConfig.Address)
+	if err != nil {
+		return newErrorResult(probe.Name(), start, err)
+	}
+
+	defer connection.Close()
+
+	certificates := connection.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return newResult(probe.Name(), start, probetype.TLS_FAILURE, "No peer certificate presented")
+	}
+
+	expiresAt := certificates[0].NotAfter
+	remaining := time.Until(expiresAt)
+	if remaining < tlsConfig.MinValidity {
+		return newResult(probe.Name(), start, probetype.TLS_FAILURE, fmt.Sprintf("Certificate expires at %s", expiresAt))
+	}
+
+	return newResult(probe.Name(), start, probetype.SUCCESS, fmt.Sprintf("Certificate valid until %s", expiresAt))
+}
@@ -0,0 +1,145 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/entity"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/event"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgetypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+const (
+	SCHEMA_VERSION = "1"
+	DEFAULT_SOURCE = "aws-infrastructure-helper"
+
+	SNS_SUBJECT_MAX_LENGTH = 100
+	EVENT_TYPE_ATTRIBUTE   = "eventType"
+)
+
+// Message is the published schema, new fields may be added but existing ones keep name and meaning
+type Message struct {
+	Version string            `json:"version"`
+	Source  string            `json:"source"`
+	Type    event.Type        `json:"type"`
+	Time    time.Time         `json:"time"`
+	Group   string            `json:"group,omitempty"`
+	Link    string            `json:"link,omitempty"`
+	Message string            `json:"message"`
+	Code    string            `json:"code,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+type Publisher struct {
+	config config.AwsEvents
+}
+
+func NewPublisher(eventsConfig config.AwsEvents) *Publisher {
+	if utils.IsEmptyStr(eventsConfig.Source) {
+		eventsConfig.Source = DEFAULT_SOURCE
+	}
+
+	return &Publisher{
+		config: eventsConfig,
+	}
+}
+
+func (publisher *Publisher) IsEnabled(eventType event.Type) bool {
+	eventsConfig := publisher.config
+	if utils.IsEmptyStr(eventsConfig.Sns.TopicArn) && utils.IsEmptyStr(eventsConfig.EventBridge.BusName) {
+		return false
+	}
+
+	return len(eventsConfig.Types) == 0 || slices.Contains(eventsConfig.Types, eventType)
+}
+
+func (publisher *Publisher) Publish(ctx *context.Context, awsConfig *aws.Config, helperEvent entity.Event) error {
+	message := Message{
+		Version: SCHEMA_VERSION,
+		Source:  publisher.config.Source,
+		Type:    helperEvent.Type,
+		Time:    helperEvent.Time.UTC(),
+		Group:   helperEvent.Group,
+		Link:    helperEvent.Link,
+		Message: helperEvent.Message,
+		Code:    helperEvent.Code,
+		Details: helperEvent.Details,
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	if utils.IsNotEmptyStr(publisher.config.Sns.TopicArn) {
+		err = publisher.publishSns(ctx, awsConfig, helperEvent, string(data))
+		if err != nil {
+			return err
+		}
+	}
+
+	if utils.IsNotEmptyStr(publisher.config.EventBridge.BusName) {
+		err = publisher.publishEventBridge(ctx, awsConfig, helperEvent, string(data))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (publisher *Publisher) publishSns(ctx *context.Context, awsConfig *aws.Config, helperEvent entity.Event, data string) error {
+	subject := helperEvent.Title()
+	if len(subject) > SNS_SUBJECT_MAX_LENGTH {
+		subject = subject[:SNS_SUBJECT_MAX_LENGTH]
+	}
+
+	client := sns.NewFromConfig(*awsConfig)
+	_, err := client.Publish(*ctx, &sns.PublishInput{
+		TopicArn: aws.String(publisher.config.Sns.TopicArn),
+		Subject:  aws.String(subject),
+		Message:  aws.String(data),
+		MessageAttributes: map[string]snstypes.MessageAttributeValue{
+			EVENT_TYPE_ATTRIBUTE: {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(string(helperEvent.Type)),
+			},
+		},
+	})
+
+	return err
+}
+
+func (publisher *Publisher) publishEventBridge(ctx *context.Context, awsConfig *aws.Config, helperEvent entity.Event, data string) error {
+	client := eventbridge.NewFromConfig(*awsConfig)
+	output, err := client.PutEvents(*ctx, &eventbridge.PutEventsInput{
+		Entries: []eventbridgetypes.PutEventsRequestEntry{
+			{
+				EventBusName: aws.String(publisher.config.EventBridge.BusName),
+				Source:       aws.String(publisher.config.Source),
+				DetailType:   aws.String(string(helperEvent.Type)),
+				Detail:       aws.String(data),
+				Time:         aws.Time(helperEvent.Time),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if output.FailedEntryCount > 0 {
+		entry := output.Entries[0]
+		return errors.New("EventBridge entry failed: " + aws.ToString(entry.ErrorCode) + ": " + aws.ToString(entry.ErrorMessage))
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/quiet"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const CLOCK_LAYOUT = "15:04"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// QuietWindow is a daily time range, an end before the start crosses midnight and belongs to the start day
+type QuietWindow struct {
+	config   config.QuietWindow
+	days     map[time.Weekday]bool
+	start    int
+	end      int
+	location *time.Location
+}
+
+func NewQuietWindows(windowsConfig []config.QuietWindow) ([]*QuietWindow, error) {
+	var windows []*QuietWindow
+
+	for index, windowConfig := range windowsConfig {
+		if utils.IsEmptyStr(windowConfig.Name) {
+			windowConfig.Name = fmt.Sprintf("quiet-window-%d", index+1)
+		}
+
+		window, err := newQuietWindow(windowConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+func newQuietWindow(windowConfig config.QuietWindow) (*QuietWindow, error) {
+	if windowConfig.Mode == "" {
+		windowConfig.Mode = quiet.SUPPRESS
+	}
+
+	if windowConfig.Mode != quiet.SUPPRESS && windowConfig.Mode != quiet.NOTIFY {
+		return nil, fmt.Errorf("Invalid mode of quiet window %s: %s", windowConfig.Name, windowConfig.Mode)
+	}
+
+	start, err := parseClock(windowConfig.Start)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid start of quiet window %s: %s", windowConfig.Name, windowConfig.Start)
+	}
+
+	end, err := parseClock(windowConfig.End)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid end of quiet window %s: %s", windowConfig.Name, windowConfig.End)
+	}
+
+	location := time.Local
+	if utils.IsNotEmptyStr(windowConfig.Timezone) {
+		location, err = time.LoadLocation(windowConfig.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid timezone of quiet window %s: %s", windowConfig.Name, err.Error())
+		}
+	}
+
+	days := make(map[time.Weekday]bool)
+	for _, day := range windowConfig.Days {
+		weekday, exists := weekdays[strings.ToLower(day)[:min(len(day), 3)]]
+		if !exists {
+			return nil, fmt.Errorf("Invalid day of quiet window %s: %s", windowConfig.Name, day)
+		}
+
+		days[weekday] = true
+	}
+
+	return &QuietWindow{
+		config:   windowConfig,
+		days:     days,
+		start:    start,
+		end:      end,
+		location: location,
+	}, nil
+}
+
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse(CLOCK_LAYOUT, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+func (window *QuietWindow) Name() string {
+	return window.config.Name
+}
+
+func (window *QuietWindow) Mode() quiet.Mode {
+	return window.config.Mode
+}
+
+func (window *QuietWindow) Contains(now time.Time) bool {
+	local := now.In(window.location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	switch {
+	case window.start == window.end:
+		return window.isDay(day)
+
+	case window.start < window.end:
+		return window.isDay(day) && minute >= window.start && minute < window.end
+
+	case minute >= window.start:
+		return window.isDay(day)
+
+	case minute < window.end:
+		return window.isDay((day + 6) % 7)
+	}
+
+	return false
+}
+
+func (window *QuietWindow) isDay(day time.Weekday) bool {
+	return len(window.days) == 0 || window.days[day]
+}
+
+func ActiveQuietWindow(windows []*QuietWindow, now time.Time) *QuietWindow {
+	for _, window := range windows {
+		if window.Contains(now) {
+			return window
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/quiet"
+	"testing"
+	"time"
+)
+
+func TestNewQuietWindows(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.QuietWindow
+		valid  bool
+	}{
+		{"minimal", config.QuietWindow{Start: "22:00", End: "06:00"}, true},
+		{"full", config.QuietWindow{Days: []string{"Monday", "fri"}, Start: "08:00", End: "18:00", Timezone: "America/Sao_Paulo", Mode: quiet.NOTIFY}, true},
+		{"invalid start", config.QuietWindow{Start: "25:00", End: "06:00"}, false},
+		{"invalid end", config.QuietWindow{Start: "22:00", End: "6pm"}, false},
+		{"invalid day", config.QuietWindow{Days: []string{"xyz"}, Start: "22:00", End: "06:00"}, false},
+		{"invalid timezone", config.QuietWindow{Start: "22:00", End: "06:00", Timezone: "Mars/Base"}, false},
+		{"invalid mode", config.QuietWindow{Start: "22:00", End: "06:00", Mode: "mute"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			windows, err := NewQuietWindows([]config.QuietWindow{test.config})
+			if (err == nil) != test.valid {
+				t.Fatalf("NewQuietWindows valid = %t, expected %t (error %v)", err == nil, test.valid, err)
+			}
+
+			if test.valid && windows[0].Name() != "quiet-window-1" {
+				t.Errorf("default name = %s", windows[0].Name())
+			}
+		})
+	}
+}
+
+func TestQuietWindowContains(t *testing.T) {
+	// 2024-03-08 is a Friday
+	at := func(day int, hour int, minute int) time.Time {
+		return time.Date(2024, time.March, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		config   config.QuietWindow
+		now      time.Time
+		expected bool
+	}{
+		{"same day inside", config.QuietWindow{Start: "08:00", End: "18:00"}, at(8, 12, 0), true},
+		{"same day at start", config.QuietWindow{Start: "08:00", End: "18:00"}, at(8, 8, 0), true},
+		{"same day at end", config.QuietWindow{Start: "08:00", End: "18:00"}, at(8, 18, 0), false},
+		{"midnight before", config.QuietWindow{Start: "22:00", End: "06:00"}, at(8, 23, 30), true},
+		{"midnight after", config.QuietWindow{Start: "22:00", End: "06:00"}, at(9, 5, 59), true},
+		{"midnight outside", config.QuietWindow{Start: "22:00", End: "06:00"}, at(9, 6, 0), false},
+		{"midnight after belongs to start day", config.QuietWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"}, at(9, 2, 0), true},
+		{"midnight after of other day", config.QuietWindow{Days: []string{"sat"}, Start: "22:00", End: "06:00"}, at(9, 2, 0), false},
+		{"day filter", config.QuietWindow{Days: []string{"mon"}, Start: "08:00", End: "18:00"}, at(8, 12, 0), false},
+		{"whole day", config.QuietWindow{Days: []string{"sat", "sun"}, Start: "00:00", End: "00:00"}, at(10, 15, 0), true},
+		{"sunday to monday", config.QuietWindow{Days: []string{"sun"}, Start: "23:00", End: "01:00"}, at(11, 0, 30), true},
+		{"timezone inside", config.QuietWindow{Start: "22:00", End: "06:00", Timezone: "America/Sao_Paulo"}, at(9, 1, 30), true},
+		{"timezone outside", config.QuietWindow{Start: "22:00", End: "06:00", Timezone: "America/Sao_Paulo"}, at(9, 9, 30), false},
+		{"timezone day", config.QuietWindow{Days: []string{"fri"}, Start: "20:00", End: "23:00", Timezone: "America/Sao_Paulo"}, at(9, 0, 30), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// windows without timezone follow the local one, pinned so the test does not depend on the host
+			if test.config.Timezone == "" {
+				test.config.Timezone = "UTC"
+			}
+
+			window, err := newQuietWindow(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if contains := window.Contains(test.now); contains != test.expected {
+				t.Errorf("Contains(%s) = %t, expected %t", test.now, contains, test.expected)
+			}
+		})
+	}
+}
+
+func TestActiveQuietWindow(t *testing.T) {
+	windows, err := NewQuietWindows([]config.QuietWindow{
+		{Name: "night", Start: "22:00", End: "06:00", Timezone: "UTC"},
+		{Name: "lunch", Start: "12:00", End: "13:00", Timezone: "UTC"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if window := ActiveQuietWindow(windows, time.Date(2024, time.March, 8, 12, 30, 0, 0, time.UTC)); window == nil || window.Name() != "lunch" {
+		t.Errorf("expected lunch window, got %v", window)
+	}
+
+	if window := ActiveQuietWindow(windows, time.Date(2024, time.March, 8, 15, 0, 0, 0, time.UTC)); window != nil {
+		t.Errorf("expected no window, got %s", window.Name())
+	}
+}
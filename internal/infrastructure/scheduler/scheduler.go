@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Scheduler runs a task on a fixed interval or on a cron expression, runs never overlap
+// and a run that took longer than the period is followed by the next one right away
+type Scheduler struct {
+	name     string
+	schedule config.Schedule
+	cron     cron.Schedule
+}
+
+func NewScheduler(name string, schedule config.Schedule) (*Scheduler, error) {
+	scheduler := &Scheduler{
+		name:     name,
+		schedule: schedule,
+	}
+
+	if schedule.Jitter < 0 {
+		return nil, fmt.Errorf("Invalid jitter of %s: %s", name, schedule.Jitter)
+	}
+
+	if utils.IsNotEmptyStr(schedule.Cron) {
+		// standard five fields, descriptors like @hourly and an optional CRON_TZ= prefix
+		cronSchedule, err := cron.ParseStandard(schedule.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid cron expression of %s: %s", name, err.Error())
+		}
+
+		scheduler.cron = cronSchedule
+		return scheduler, nil
+	}
+
+	if schedule.CheckInterval <= 0 {
+		return nil, errors.New("Missing check-interval or cron of " + name)
+	}
+
+	return scheduler, nil
+}
+
+func (scheduler *Scheduler) String() string {
+	description := "every " + scheduler.schedule.CheckInterval.String()
+	if scheduler.cron != nil {
+		description = "on cron " + scheduler.schedule.Cron
+	}
+
+	if scheduler.schedule.Jitter > 0 {
+		description += " with jitter up to " + scheduler.schedule.Jitter.String()
+	}
+
+	return description
+}
+
+// Start runs the task in background until the context is done
+func (scheduler *Scheduler) Start(ctx *context.Context, task func()) {
+	log.Info(ctx).Msg(fmt.Sprintf("Scheduling %s %s", scheduler.name, scheduler))
+
+	go func() {
+		if !scheduler.schedule.DelayFirstRun {
+			task()
+		}
+
+		planned := time.Now()
+		for {
+			planned = scheduler.following(planned, time.Now())
+
+			timer := time.NewTimer(time.Until(planned) + scheduler.jitter())
+
+			select {
+			case <-(*ctx).Done():
+				timer.Stop()
+				log.Info(ctx).Msg(fmt.Sprintf("Stopping %s", scheduler.name))
+				return
+
+			case <-timer.C:
+				task()
+			}
+		}
+	}()
+}
+
+// following plans the run after the planned one, a run that overran the next planned time
+// is followed right away and the missed runs collapse into this single one
+func (scheduler *Scheduler) following(planned time.Time, now time.Time) time.Time {
+	planned = scheduler.next(planned)
+	if planned.Before(now) {
+		return now
+	}
+
+	return planned
+}
+
+func (scheduler *Scheduler) next(from time.Time) time.Time {
+	if scheduler.cron != nil {
+		return scheduler.cron.Next(from)
+	}
+
+	return from.Add(scheduler.schedule.CheckInterval)
+}
+
+func (scheduler *Scheduler) jitter() time.Duration {
+	if scheduler.schedule.Jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(scheduler.schedule.Jitter)))
+}
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewScheduler(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule config.Schedule
+		valid    bool
+	}{
+		{"interval", config.Schedule{CheckInterval: 10 * time.Second}, true},
+		{"cron", config.Schedule{Cron: "*/5 * * * *"}, true},
+		{"cron with timezone", config.Schedule{Cron: "CRON_TZ=America/Sao_Paulo 0 8 * * 1-5"}, true},
+		{"descriptor", config.Schedule{Cron: "@hourly"}, true},
+		{"missing schedule", config.Schedule{}, false},
+		{"invalid cron", config.Schedule{Cron: "* * *"}, false},
+		{"negative jitter", config.Schedule{CheckInterval: time.Second, Jitter: -time.Second}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewScheduler(test.name, test.schedule)
+			if (err == nil) != test.valid {
+				t.Errorf("NewScheduler valid = %t, expected %t (error %v)", err == nil, test.valid, err)
+			}
+		})
+	}
+}
+
+func TestFollowing(t *testing.T) {
+	base := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule config.Schedule
+		planned  time.Time
+		now      time.Time
+		expected time.Time
+	}{
+		{"interval on time", config.Schedule{CheckInterval: time.Minute}, base, base.Add(10 * time.Second), base.Add(time.Minute)},
+		{"interval overrun", config.Schedule{CheckInterval: time.Minute}, base, base.Add(3 * time.Minute), base.Add(3 * time.Minute)},
+		{"cron on time", config.Schedule{Cron: "*/5 * * * *"}, base, base.Add(time.Minute), base.Add(5 * time.Minute)},
+		{"cron overrun", config.Schedule{Cron: "*/5 * * * *"}, base, base.Add(12 * time.Minute), base.Add(12 * time.Minute)},
+		{"cron timezone", config.Schedule{Cron: "CRON_TZ=America/Sao_Paulo 0 8 * * *"}, base, base, time.Date(2024, time.March, 11, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheduler, err := NewScheduler(test.name, test.schedule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if following := scheduler.following(test.planned, test.now); !following.Equal(test.expected) {
+				t.Errorf("following = %s, expected %s", following, test.expected)
+			}
+		})
+	}
+}
+
+func TestJitter(t *testing.T) {
+	scheduler, err := NewScheduler("jitter", config.Schedule{CheckInterval: time.Minute, Jitter: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if jitter := scheduler.jitter(); jitter < 0 || jitter >= time.Second {
+			t.Fatalf("jitter %s out of range", jitter)
+		}
+	}
+
+	scheduler.schedule.Jitter = 0
+	if jitter := scheduler.jitter(); jitter != 0 {
+		t.Errorf("jitter = %s, expected none", jitter)
+	}
+}
+
+func TestStartStopsOnContextDone(t *testing.T) {
+	scheduler, err := NewScheduler("stop", config.Schedule{CheckInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler.Start(&ctx, func() {
+		runs.Add(1)
+	})
+
+	time.Sleep(55 * time.Millisecond)
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	stopped := runs.Load()
+	if stopped < 2 {
+		t.Errorf("only %d runs before stop", stopped)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Errorf("scheduler kept running after the context was done")
+	}
+}
+
+func TestDelayFirstRun(t *testing.T) {
+	scheduler, err := NewScheduler("delay", config.Schedule{CheckInterval: time.Hour, DelayFirstRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheduler.Start(&ctx, func() {
+		runs.Add(1)
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	if runs.Load() != 0 {
+		t.Errorf("first run was not delayed")
+	}
+}
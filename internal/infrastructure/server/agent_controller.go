@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/prober"
+	"net/http"
+)
+
+type AgentController struct {
+	probes []prober.Probe
+	tokens []string
+}
+
+func NewAgentController(probes []prober.Probe, tokens []string) *AgentController {
+	return &AgentController{
+		probes: probes,
+		tokens: tokens,
+	}
+}
+
+func (controller *AgentController) Check(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if request.Method != http.MethodGet {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if !isBearerAuthorized(request, controller.tokens) {
+		log.Warn(&ctx).Msg("Unauthorized agent check from " + request.RemoteAddr)
+		writeError(writer, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	probeName := request.URL.Query().Get("probe")
+	if utils.IsEmptyStr(probeName) {
+		writeJSON(writer, http.StatusOK, controller.checkAll(&ctx))
+		return
+	}
+
+	for _, probe := range controller.probes {
+		if probe.Name() == probeName {
+			writeJSON(writer, http.StatusOK, probe.Check(&ctx))
+			return
+		}
+	}
+
+	writeError(writer, http.StatusNotFound, "Probe not found: "+probeName)
+}
+
+func (controller *AgentController) checkAll(ctx *context.Context) []prober.Result {
+	results := make([]prober.Result, 0, len(controller.probes))
+	for _, probe := range controller.probes {
+		results = append(results, probe.Check(ctx))
+	}
+
+	return results
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAgentCheckAuthorization(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokens        []string
+		authorization string
+		expected      int
+	}{
+		{"valid token", []string{"secret"}, "Bearer secret", http.StatusOK},
+		{"wrong token", []string{"secret"}, "Bearer other", http.StatusUnauthorized},
+		{"missing header", []string{"secret"}, "", http.StatusUnauthorized},
+		{"empty bearer against empty token", []string{""}, "Bearer ", http.StatusUnauthorized},
+		{"empty bearer next to valid token", []string{"", "secret"}, "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			controller := NewAgentController(nil, test.tokens)
+
+			request := httptest.NewRequest(http.MethodGet, api.AGENT_CHECK_PATH, nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			controller.Check(recorder, request)
+
+			if recorder.Code != test.expected {
+				t.Errorf("status %d, expected %d", recorder.Code, test.expected)
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		listening string
+		expected  bool
+	}{
+		{"127.0.0.1:8081", true},
+		{"[::1]:8081", true},
+		{"localhost:8081", true},
+		{"0.0.0.0:8081", false},
+		{":8081", false},
+		{"203.0.113.10:8081", false},
+		{"8081", false},
+	}
+
+	for _, test := range tests {
+		if loopback := isLoopback(test.listening); loopback != test.expected {
+			t.Errorf("isLoopback(%s) = %t, expected %t", test.listening, loopback, test.expected)
+		}
+	}
+}
+
+func TestNewAgentServerValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		listening string
+		tokens    []string
+		valid     bool
+	}{
+		{"loopback without tls", "127.0.0.1:8081", []string{"secret"}, true},
+		{"public without tls", "0.0.0.0:8081", []string{"secret"}, false},
+		{"empty token", "127.0.0.1:8081", []string{"secret", ""}, false},
+		{"no token", "127.0.0.1:8081", nil, false},
+	}
+
+	defer func(previous config.Config) { config.ApplicationConfig = previous }(config.ApplicationConfig)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config.ApplicationConfig = config.Config{}
+			config.ApplicationConfig.Agent.Listening = test.listening
+			config.ApplicationConfig.Agent.Tokens = test.tokens
+
+			_, err := NewAgentServer()
+			if (err == nil) != test.valid {
+				t.Errorf("NewAgentServer valid = %t, expected %t (error %v)", err == nil, test.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/service"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/audit"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditController struct {
+	helperService *service.HelperService
+}
+
+func NewAuditController(helperService *service.HelperService) *AuditController {
+	return &AuditController{
+		helperService: helperService,
+	}
+}
+
+func (controller *AuditController) GetEntries(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if request.Method != http.MethodGet {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := request.URL.Query()
+
+	since, err := audit.ParseSince(query.Get("since"), time.Now())
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := audit.Filter{
+		Since:    since,
+		Action:   query.Get("action"),
+		Resource: query.Get("resource"),
+		Group:    query.Get("group"),
+	}
+
+	if limit := query.Get("limit"); utils.IsNotEmptyStr(limit) {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, "Invalid limit: "+limit)
+			return
+		}
+	}
+
+	entries, err := controller.helperService.GetAuditEntries(filter)
+	if err != nil {
+		log.Error(&ctx).Msg("Error on reading audit journal: " + err.Error())
+		writeError(writer, http.StatusInternalServerError, "Error on reading audit journal")
+		return
+	}
+
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"entries": entries,
+	})
+}
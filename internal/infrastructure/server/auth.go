@@ -0,0 +1,285 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/role"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	BEARER_PREFIX = "Bearer "
+	BASIC_REALM   = `Basic realm="aws-infrastructure-helper"`
+
+	AUTH_METHOD_TOKEN       = "token"
+	AUTH_METHOD_BASIC       = "basic"
+	AUTH_METHOD_CERTIFICATE = "certificate"
+
+	JSON_MEDIA_TYPE        = "application/json"
+	FETCH_SITE_SAME_ORIGIN = "same-origin"
+	FETCH_SITE_NONE        = "none"
+)
+
+type principal struct {
+	name   string
+	role   role.Type
+	method string
+}
+
+// Authenticator resolves the caller from a client certificate, a bearer token or HTTP Basic credentials.
+// Without any configured credential every endpoint is denied, unless anonymous-read opens the read-only ones.
+// Admin endpoints only accept same-origin requests with a JSON body.
+type Authenticator struct {
+	auth config.ServerAuth
+}
+
+func NewAuthenticator(auth config.ServerAuth) (*Authenticator, error) {
+	for index, token := range auth.Tokens {
+		if utils.IsEmptyStr(token.Token) {
+			return nil, fmt.Errorf("Server auth token %d is empty", index+1)
+		}
+
+		err := validateRole(token.Role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, user := range auth.Users {
+		if utils.IsEmptyStr(user.Username) {
+			return nil, errors.New("Server auth user without username")
+		}
+
+		_, err := bcrypt.Cost([]byte(user.PasswordHash))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid bcrypt password hash of user %s: %s", user.Username, err.Error())
+		}
+
+		err = validateRole(user.Role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, client := range auth.Clients {
+		if utils.IsEmptyStr(client.CommonName) {
+			return nil, errors.New("Server auth client without common name")
+		}
+
+		err := validateRole(client.Role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Authenticator{
+		auth: auth,
+	}, nil
+}
+
+func validateRole(roleType role.Type) error {
+	if roleType != role.READ_ONLY && roleType != role.ADMIN {
+		return fmt.Errorf("Invalid server auth role: %s", roleType)
+	}
+
+	return nil
+}
+
+func hasRole(granted role.Type, required role.Type) bool {
+	return granted == role.ADMIN || granted == required
+}
+
+func (authenticator *Authenticator) isEnabled() bool {
+	auth := authenticator.auth
+	return len(auth.Tokens) > 0 || len(auth.Users) > 0 || len(auth.Clients) > 0
+}
+
+// authorize wraps a handler requiring the given role, admin requests are logged with the caller
+func (authenticator *Authenticator) authorize(required role.Type, handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+
+		if !authenticator.isEnabled() {
+			if required == role.ADMIN {
+				writeError(writer, http.StatusForbidden, "Admin actions are disabled, configure server auth to enable them")
+				return
+			}
+
+			// status and audit expose public IPs and AWS resource ids, so they are not served by default
+			if !authenticator.auth.AnonymousRead {
+				writeError(writer, http.StatusForbidden, "Endpoint is disabled, configure server auth or enable anonymous-read")
+				return
+			}
+
+			handler(writer, request)
+			return
+		}
+
+		if required == role.ADMIN {
+			status, message := checkAdminRequest(request)
+			if status != http.StatusOK {
+				log.Warn(&ctx).Msg(fmt.Sprintf("Rejected admin request %s %s from %s: %s", request.Method, request.URL.Path, request.RemoteAddr, message))
+				writeError(writer, status, message)
+				return
+			}
+		}
+
+		caller := authenticator.authenticate(request)
+		if caller == nil {
+			log.Warn(&ctx).Msg(fmt.Sprintf("Unauthorized request %s %s from %s", request.Method, request.URL.Path, request.RemoteAddr))
+
+			if len(authenticator.auth.Users) > 0 {
+				writer.Header().Set("WWW-Authenticate", BASIC_REALM)
+			}
+
+			writeError(writer, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		if !hasRole(caller.role, required) {
+			log.Warn(&ctx).Msg(fmt.Sprintf("Forbidden request %s %s from %s with role %s", request.Method, request.URL.Path, caller.name, caller.role))
+			writeError(writer, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		callerCtx := log.WithTrace(&ctx, log.TRACE_PRINCIPAL, caller.name)
+
+		if required == role.ADMIN {
+			log.Info(callerCtx).
+				PutTraceMap("authMethod", caller.method).
+				PutTraceMap("role", string(caller.role)).
+				PutTraceMap("remoteAddr", request.RemoteAddr).
+				Msg(fmt.Sprintf("Admin action %s %s by %s", request.Method, request.URL.Path, caller.name))
+		}
+
+		handler(writer, request.WithContext(*callerCtx))
+	}
+}
+
+// checkAdminRequest rejects cross-site requests, browsers replay cached Basic credentials on them,
+// and a form posting text/plain could otherwise carry a JSON body to an admin endpoint
+func checkAdminRequest(request *http.Request) (int, string) {
+	fetchSite := request.Header.Get("Sec-Fetch-Site")
+	if utils.IsNotEmptyStr(fetchSite) {
+		if fetchSite != FETCH_SITE_SAME_ORIGIN && fetchSite != FETCH_SITE_NONE {
+			return http.StatusForbidden, "Cross-site admin requests are not allowed"
+		}
+	} else if origin := request.Header.Get("Origin"); utils.IsNotEmptyStr(origin) {
+		originUrl, err := url.Parse(origin)
+		if err != nil || originUrl.Host != request.Host {
+			return http.StatusForbidden, "Cross-site admin requests are not allowed"
+		}
+	}
+
+	if request.Method == http.MethodPost || request.Method == http.MethodPut || request.Method == http.MethodPatch {
+		mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		if err != nil || mediaType != JSON_MEDIA_TYPE {
+			return http.StatusUnsupportedMediaType, "Content-Type must be " + JSON_MEDIA_TYPE
+		}
+	}
+
+	return http.StatusOK, ""
+}
+
+func (authenticator *Authenticator) authenticate(request *http.Request) *principal {
+	if caller := authenticator.authenticateCertificate(request); caller != nil {
+		return caller
+	}
+
+	authorization := request.Header.Get("Authorization")
+	if strings.HasPrefix(authorization, BEARER_PREFIX) {
+		return authenticator.authenticateToken(strings.TrimPrefix(authorization, BEARER_PREFIX))
+	}
+
+	username, password, ok := request.BasicAuth()
+	if ok {
+		return authenticator.authenticateBasic(username, password)
+	}
+
+	return nil
+}
+
+func (authenticator *Authenticator) authenticateCertificate(request *http.Request) *principal {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	commonName := request.TLS.VerifiedChains[0][0].Subject.CommonName
+	for _, client := range authenticator.auth.Clients {
+		if client.CommonName == commonName {
+			return &principal{
+				name:   commonName,
+				role:   client.Role,
+				method: AUTH_METHOD_CERTIFICATE,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (authenticator *Authenticator) authenticateToken(token string) *principal {
+	for index, allowed := range authenticator.auth.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(allowed.Token)) == 1 {
+			name := allowed.Name
+			if utils.IsEmptyStr(name) {
+				name = fmt.Sprintf("token-%d", index+1)
+			}
+
+			return &principal{
+				name:   name,
+				role:   allowed.Role,
+				method: AUTH_METHOD_TOKEN,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (authenticator *Authenticator) authenticateBasic(username string, password string) *principal {
+	for _, user := range authenticator.auth.Users {
+		if user.Username != username {
+			continue
+		}
+
+		err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+		if err != nil {
+			return nil
+		}
+
+		return &principal{
+			name:   username,
+			role:   user.Role,
+			method: AUTH_METHOD_BASIC,
+		}
+	}
+
+	return nil
+}
+
+func isBearerAuthorized(request *http.Request, tokens []string) bool {
+	authorization := request.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, BEARER_PREFIX) {
+		return false
+	}
+
+	token := []byte(strings.TrimPrefix(authorization, BEARER_PREFIX))
+	for _, allowed := range tokens {
+		// comparing two empty slices matches, an empty token never authorizes
+		if len(allowed) > 0 && subtle.ConstantTimeCompare(token, []byte(allowed)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
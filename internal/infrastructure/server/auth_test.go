@@ -0,0 +1,200 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/role"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func verifiedCertificate(commonName string) *tls.ConnectionState {
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{certificate},
+		VerifiedChains:   [][]*x509.Certificate{{certificate}},
+	}
+}
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("admin-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := NewAuthenticator(config.ServerAuth{
+		Tokens: []config.AuthToken{
+			{Name: "monitoring", Token: "read-token", Role: role.READ_ONLY},
+			{Token: "admin-token", Role: role.ADMIN},
+		},
+		Users: []config.AuthUser{
+			{Username: "admin", PasswordHash: string(passwordHash), Role: role.ADMIN},
+		},
+		Clients: []config.AuthClient{
+			{CommonName: "ops-laptop", Role: role.ADMIN},
+			{CommonName: "grafana", Role: role.READ_ONLY},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return authenticator
+}
+
+type authCase struct {
+	name     string
+	required role.Type
+	prepare  func(request *http.Request)
+	expected int
+}
+
+func runAuthCases(t *testing.T, authenticator *Authenticator, tests []authCase) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := http.MethodGet
+			if test.required == role.ADMIN {
+				method = http.MethodPost
+			}
+
+			request := httptest.NewRequest(method, "http://helper.example.com/fallback", strings.NewReader(`{"group":"home","fallback":true}`))
+			if method == http.MethodPost {
+				request.Header.Set("Content-Type", "application/json")
+			}
+
+			if test.prepare != nil {
+				test.prepare(request)
+			}
+
+			called := false
+			handler := authenticator.authorize(test.required, func(writer http.ResponseWriter, request *http.Request) {
+				called = true
+				writer.WriteHeader(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+
+			if recorder.Code != test.expected {
+				t.Errorf("status %d, expected %d (%s)", recorder.Code, test.expected, recorder.Body.String())
+			}
+
+			if called != (test.expected == http.StatusOK) {
+				t.Errorf("handler called = %t with status %d", called, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestAuthorizeWithoutCredentials(t *testing.T) {
+	closed, _ := NewAuthenticator(config.ServerAuth{})
+	anonymous, _ := NewAuthenticator(config.ServerAuth{AnonymousRead: true})
+
+	runAuthCases(t, closed, []authCase{
+		{"read denied", role.READ_ONLY, nil, http.StatusForbidden},
+		{"admin denied", role.ADMIN, nil, http.StatusForbidden},
+	})
+
+	runAuthCases(t, anonymous, []authCase{
+		{"anonymous read", role.READ_ONLY, nil, http.StatusOK},
+		{"admin still denied", role.ADMIN, nil, http.StatusForbidden},
+		{"admin denied with a token", role.ADMIN, func(request *http.Request) { request.Header.Set("Authorization", "Bearer anything") }, http.StatusForbidden},
+	})
+}
+
+func TestAuthorizeRoles(t *testing.T) {
+	bearer := func(token string) func(request *http.Request) {
+		return func(request *http.Request) { request.Header.Set("Authorization", "Bearer "+token) }
+	}
+
+	basic := func(username string, password string) func(request *http.Request) {
+		return func(request *http.Request) { request.SetBasicAuth(username, password) }
+	}
+
+	certificate := func(commonName string) func(request *http.Request) {
+		return func(request *http.Request) { request.TLS = verifiedCertificate(commonName) }
+	}
+
+	runAuthCases(t, newTestAuthenticator(t), []authCase{
+		{"no credentials", role.READ_ONLY, nil, http.StatusUnauthorized},
+		{"read token reads", role.READ_ONLY, bearer("read-token"), http.StatusOK},
+		{"read token cannot fail over", role.ADMIN, bearer("read-token"), http.StatusForbidden},
+		{"admin token fails over", role.ADMIN, bearer("admin-token"), http.StatusOK},
+		{"admin token reads", role.READ_ONLY, bearer("admin-token"), http.StatusOK},
+		{"unknown token", role.READ_ONLY, bearer("other"), http.StatusUnauthorized},
+		{"empty token", role.READ_ONLY, bearer(""), http.StatusUnauthorized},
+		{"bcrypt user", role.ADMIN, basic("admin", "admin-password"), http.StatusOK},
+		{"bcrypt wrong password", role.ADMIN, basic("admin", "wrong"), http.StatusUnauthorized},
+		{"unknown user", role.READ_ONLY, basic("guest", "admin-password"), http.StatusUnauthorized},
+		{"certificate admin", role.ADMIN, certificate("ops-laptop"), http.StatusOK},
+		{"certificate read-only", role.ADMIN, certificate("grafana"), http.StatusForbidden},
+		{"certificate unknown", role.READ_ONLY, certificate("intruder"), http.StatusUnauthorized},
+		{"certificate not verified", role.READ_ONLY, func(request *http.Request) {
+			request.TLS = &tls.ConnectionState{PeerCertificates: verifiedCertificate("ops-laptop").PeerCertificates}
+		}, http.StatusUnauthorized},
+	})
+}
+
+func TestAuthorizeBasicChallenge(t *testing.T) {
+	handler := newTestAuthenticator(t).authorize(role.READ_ONLY, func(writer http.ResponseWriter, request *http.Request) {})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	if recorder.Header().Get("WWW-Authenticate") != BASIC_REALM {
+		t.Errorf("missing basic challenge, headers %v", recorder.Header())
+	}
+}
+
+func TestAuthorizeRejectsCrossSiteAdminRequests(t *testing.T) {
+	admin := func(prepare func(request *http.Request)) func(request *http.Request) {
+		return func(request *http.Request) {
+			request.SetBasicAuth("admin", "admin-password")
+			prepare(request)
+		}
+	}
+
+	runAuthCases(t, newTestAuthenticator(t), []authCase{
+		{"text/plain form", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Content-Type", "text/plain") }), http.StatusUnsupportedMediaType},
+		{"urlencoded form", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Content-Type", "application/x-www-form-urlencoded") }), http.StatusUnsupportedMediaType},
+		{"missing content type", role.ADMIN, admin(func(request *http.Request) { request.Header.Del("Content-Type") }), http.StatusUnsupportedMediaType},
+		{"json with charset", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Content-Type", "application/json; charset=utf-8") }), http.StatusOK},
+		{"cross-site fetch", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Sec-Fetch-Site", "cross-site") }), http.StatusForbidden},
+		{"same-site fetch", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Sec-Fetch-Site", "same-site") }), http.StatusForbidden},
+		{"same-origin fetch", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Sec-Fetch-Site", "same-origin") }), http.StatusOK},
+		{"foreign origin", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Origin", "https://evil.example.net") }), http.StatusForbidden},
+		{"own origin", role.ADMIN, admin(func(request *http.Request) { request.Header.Set("Origin", "http://helper.example.com") }), http.StatusOK},
+		{"cross-site read allowed", role.READ_ONLY, func(request *http.Request) {
+			request.Header.Set("Authorization", "Bearer read-token")
+			request.Header.Set("Sec-Fetch-Site", "cross-site")
+		}, http.StatusOK},
+	})
+}
+
+func TestNewAuthenticatorValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		auth config.ServerAuth
+	}{
+		{"empty token", config.ServerAuth{Tokens: []config.AuthToken{{Token: "", Role: role.ADMIN}}}},
+		{"invalid role", config.ServerAuth{Tokens: []config.AuthToken{{Token: "t", Role: "root"}}}},
+		{"user without name", config.ServerAuth{Users: []config.AuthUser{{PasswordHash: "$2y$10$x", Role: role.ADMIN}}}},
+		{"plain password", config.ServerAuth{Users: []config.AuthUser{{Username: "admin", PasswordHash: "secret", Role: role.ADMIN}}}},
+		{"client without common name", config.ServerAuth{Clients: []config.AuthClient{{Role: role.ADMIN}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewAuthenticator(test.auth); err == nil {
+				t.Error("invalid auth accepted")
+			}
+		})
+	}
+}
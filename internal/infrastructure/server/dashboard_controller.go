@@ -0,0 +1,41 @@
+package server
+
+import (
+	_ "embed"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"net/http"
+	"strings"
+)
+
+//go:embed dashboard/index.html
+var dashboardPage []byte
+
+type DashboardController struct {
+	path string
+}
+
+func NewDashboardController() *DashboardController {
+	contextPath := strings.TrimSuffix(config.ApplicationConfig.Server.ContextPath, constants.SLASH)
+
+	return &DashboardController{
+		path: contextPath + constants.SLASH,
+	}
+}
+
+// GetDashboard serves the embedded page, it reads the JSON endpoints with relative paths
+func (controller *DashboardController) GetDashboard(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path != controller.path {
+		writeError(writer, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if request.Method != http.MethodGet {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(dashboardPage)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/service"
+	"fmt"
+	"net/http"
+)
+
+type FallbackController struct {
+	helperService *service.HelperService
+}
+
+type FallbackRequest struct {
+	Group    string `json:"group"`
+	Fallback *bool  `json:"fallback"`
+}
+
+func NewFallbackController(helperService *service.HelperService) *FallbackController {
+	return &FallbackController{
+		helperService: helperService,
+	}
+}
+
+// ChangeFallback enables or disables the fallback of a group on demand
+func (controller *FallbackController) ChangeFallback(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if request.Method != http.MethodPost {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var body FallbackRequest
+	err := json.NewDecoder(request.Body).Decode(&body)
+	if err != nil || utils.IsEmptyStr(body.Group) || body.Fallback == nil {
+		writeError(writer, http.StatusBadRequest, "Body must contain group and fallback")
+		return
+	}
+
+	log.Info(&ctx).Msg(fmt.Sprintf("Manual fallback change of group %s to %t requested from %s", body.Group, *body.Fallback, request.RemoteAddr))
+
+	errw := controller.helperService.ChangeISPFallback(&ctx, body.Group, *body.Fallback)
+	if errw != nil {
+		writeWrappedError(writer, errw)
+		return
+	}
+
+	for _, groupStatus := range controller.helperService.GetFallbackStatus() {
+		if groupStatus.Name == body.Group {
+			writeJSON(writer, http.StatusOK, groupStatus)
+			return
+		}
+	}
+}
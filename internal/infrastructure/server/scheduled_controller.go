@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/exceptions"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/service"
+	"net/http"
+	"time"
+)
+
+type ScheduledController struct {
+	helperService *service.HelperService
+}
+
+type RescheduleRequest struct {
+	Group     string     `json:"group"`
+	Id        string     `json:"id"`
+	ExecuteAt *time.Time `json:"executeAt"`
+	Delay     string     `json:"delay"`
+}
+
+func NewScheduledController(helperService *service.HelperService) *ScheduledController {
+	return &ScheduledController{
+		helperService: helperService,
+	}
+}
+
+func (controller *ScheduledController) GetScheduled(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"scheduled": controller.helperService.GetScheduledCapacities(),
+	})
+}
+
+func (controller *ScheduledController) Cancel(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	query := request.URL.Query()
+
+	group := query.Get("group")
+	id := query.Get("id")
+	if utils.IsEmptyStr(group) || utils.IsEmptyStr(id) {
+		writeError(writer, http.StatusBadRequest, "Query must contain group and id")
+		return
+	}
+
+	scheduled, errw := controller.helperService.CancelScheduledCapacity(&ctx, group, id)
+	if errw != nil {
+		writeWrappedError(writer, errw)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, scheduled)
+}
+
+// Reschedule moves a pending capacity change to a future executeAt, or to now plus a positive delay
+func (controller *ScheduledController) Reschedule(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	var body RescheduleRequest
+	err := json.NewDecoder(request.Body).Decode(&body)
+	if err != nil || utils.IsEmptyStr(body.Group) || utils.IsEmptyStr(body.Id) {
+		writeError(writer, http.StatusBadRequest, "Body must contain group and id")
+		return
+	}
+
+	var executeAt time.Time
+	if body.ExecuteAt != nil {
+		executeAt = *body.ExecuteAt
+	} else {
+		delay, err := time.ParseDuration(body.Delay)
+		if err != nil || delay <= 0 {
+			writeError(writer, http.StatusBadRequest, "Body must contain executeAt or a positive delay")
+			return
+		}
+
+		executeAt = time.Now().Add(delay)
+	}
+
+	scheduled, errw := controller.helperService.RescheduleCapacity(&ctx, body.Group, body.Id, executeAt)
+	if errw != nil {
+		writeWrappedError(writer, errw)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, scheduled)
+}
+
+func writeWrappedError(writer http.ResponseWriter, errw *exceptions.WrappedError) {
+	status := http.StatusInternalServerError
+	switch errw.GetCode() {
+	case exceptions.HTTP_CLIENT_ERROR:
+		status = http.StatusBadRequest
+	case exceptions.HTTP_NOT_FOUND:
+		status = http.StatusNotFound
+	case exceptions.PRECONDITION_FAILED:
+		status = http.StatusConflict
+	}
+
+	writeError(writer, status, errw.GetMessage())
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/constants"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/service"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/api"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config/role"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/prober"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type Server struct {
+	listening  string
+	mux        *http.ServeMux
+	httpServer *http.Server
+	tls        *config.ServerTls
+	tlsConfig  *tls.Config
+}
+
+func NewServer(helperService *service.HelperService) (*Server, error) {
+	serverConfig := config.ApplicationConfig.Server
+	server := newServer(serverConfig.Listening)
+
+	err := server.configureTls(serverConfig.Tls, len(serverConfig.Auth.Clients) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticator, err := NewAuthenticator(serverConfig.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	statusController := NewStatusController(helperService)
+	server.handle("/status", authenticator.authorize(role.READ_ONLY, statusController.GetStatus))
+
+	auditController := NewAuditController(helperService)
+	server.handle("/audit", authenticator.authorize(role.READ_ONLY, auditController.GetEntries))
+
+	uptimeController := NewUptimeController(helperService)
+	server.handle("/uptime", authenticator.authorize(role.READ_ONLY, uptimeController.GetReport))
+
+	fallbackController := NewFallbackController(helperService)
+	server.handle("/fallback", authenticator.authorize(role.ADMIN, fallbackController.ChangeFallback))
+
+	scheduledController := NewScheduledController(helperService)
+	server.handle("/scheduled", byMethod(map[string]http.HandlerFunc{
+		http.MethodGet:    authenticator.authorize(role.READ_ONLY, scheduledController.GetScheduled),
+		http.MethodDelete: authenticator.authorize(role.ADMIN, scheduledController.Cancel),
+		http.MethodPost:   authenticator.authorize(role.ADMIN, scheduledController.Reschedule),
+	}))
+
+	dashboardController := NewDashboardController()
+	server.handle(constants.SLASH, dashboardController.GetDashboard)
+
+	return server, nil
+}
+
+func NewAgentServer() (*Server, error) {
+	agentConfig := config.ApplicationConfig.Agent
+
+	listening := agentConfig.Listening
+	if utils.IsEmptyStr(listening) {
+		listening = config.ApplicationConfig.Server.Listening
+	}
+
+	if utils.IsEmptyStr(listening) {
+		return nil, errors.New("Agent mode requires a listening address")
+	}
+
+	if len(agentConfig.Tokens) == 0 {
+		return nil, errors.New("Agent mode requires at least one token")
+	}
+
+	for index, token := range agentConfig.Tokens {
+		if utils.IsEmptyStr(token) {
+			return nil, fmt.Errorf("Agent token %d is empty", index+1)
+		}
+	}
+
+	server := newServer(listening)
+
+	err := server.configureTls(agentConfig.Tls, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// the agent faces the internet from its vantage point, tokens must not travel in plain text
+	if server.tls == nil && !isLoopback(listening) {
+		return nil, fmt.Errorf("Agent listening on %s requires tls cert-file and key-file, only loopback addresses may serve plain HTTP", listening)
+	}
+
+	probes, err := prober.NewProbes(agentConfig.Probes, api.NewFetcherApi(), api.NewAgentApi())
+	if err != nil {
+		return nil, err
+	}
+
+	agentController := NewAgentController(probes, agentConfig.Tokens)
+	server.handle(api.AGENT_CHECK_PATH, agentController.Check)
+
+	return server, nil
+}
+
+func isLoopback(listening string) bool {
+	host, _, err := net.SplitHostPort(listening)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func newServer(listening string) *Server {
+	return &Server{
+		listening: listening,
+		mux:       http.NewServeMux(),
+	}
+}
+
+// configureTls enables HTTPS, with a client CA the certificates are verified and mapped to auth clients
+func (server *Server) configureTls(tlsConfig config.ServerTls, hasClients bool) error {
+	if utils.IsEmptyStr(tlsConfig.CertFile) && utils.IsEmptyStr(tlsConfig.KeyFile) {
+		if utils.IsNotEmptyStr(tlsConfig.ClientCaFile) || hasClients {
+			return errors.New("Client certificate auth requires server tls cert-file and key-file")
+		}
+
+		return nil
+	}
+
+	if utils.IsEmptyStr(tlsConfig.CertFile) || utils.IsEmptyStr(tlsConfig.KeyFile) {
+		return errors.New("Server tls requires both cert-file and key-file")
+	}
+
+	server.tls = &tlsConfig
+	server.tlsConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if utils.IsEmptyStr(tlsConfig.ClientCaFile) {
+		if hasClients || tlsConfig.RequireClientCert {
+			return errors.New("Client certificate auth requires server tls client-ca-file")
+		}
+
+		return nil
+	}
+
+	caPem, err := os.ReadFile(tlsConfig.ClientCaFile)
+	if err != nil {
+		return errors.New("Error on reading client CA file: " + err.Error())
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPem) {
+		return errors.New("No certificate found in client CA file " + tlsConfig.ClientCaFile)
+	}
+
+	server.tlsConfig.ClientCAs = clientCAs
+	server.tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if tlsConfig.RequireClientCert {
+		server.tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return nil
+}
+
+func (server *Server) handle(path string, handler http.HandlerFunc) {
+	contextPath := strings.TrimSuffix(config.ApplicationConfig.Server.ContextPath, constants.SLASH)
+	server.mux.HandleFunc(contextPath+path, func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		requestCtx := log.WithTrace(&ctx, log.TRACE_REQUEST_ID, log.NewTraceId())
+
+		handler(writer, request.WithContext(*requestCtx))
+	})
+}
+
+func byMethod(handlers map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		handler, exists := handlers[request.Method]
+		if !exists {
+			writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		handler(writer, request)
+	}
+}
+
+func (server *Server) Start(ctx *context.Context) error {
+	if utils.IsEmptyStr(server.listening) {
+		log.Info(ctx).Msg("HTTP server disabled")
+		return nil
+	}
+
+	server.httpServer = &http.Server{
+		Addr:      server.listening,
+		Handler:   server.mux,
+		TLSConfig: server.tlsConfig,
+	}
+
+	go func() {
+		var err error
+
+		if server.tls != nil {
+			log.Info(ctx).Msg(fmt.Sprintf("Starting HTTPS server on %s", server.listening))
+			err = server.httpServer.ListenAndServeTLS(server.tls.CertFile, server.tls.KeyFile)
+		} else {
+			log.Info(ctx).Msg(fmt.Sprintf("Starting HTTP server on %s", server.listening))
+			err = server.httpServer.ListenAndServe()
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(ctx).Msg("Error on starting HTTP server: " + err.Error())
+		}
+	}()
+
+	return nil
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(body)
+}
+
+func writeError(writer http.ResponseWriter, status int, message string) {
+	writeJSON(writer, status, map[string]string{
+		"message": message,
+	})
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/core/service"
+	"net/http"
+)
+
+type StatusController struct {
+	helperService *service.HelperService
+}
+
+func NewStatusController(helperService *service.HelperService) *StatusController {
+	return &StatusController{
+		helperService: helperService,
+	}
+}
+
+func (controller *StatusController) GetStatus(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	status := map[string]any{
+		"election":       controller.helperService.GetElectionStatus(),
+		"dnsLinks":       controller.helperService.GetDNSStatus(),
+		"fallbackGroups": controller.helperService.GetFallbackStatus(),
+	}
+
+	writeJSON(writer, http.StatusOK, status)
+}
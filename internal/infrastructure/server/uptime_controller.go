@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/service"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/uptime"
+	"net/http"
+	"time"
+)
+
+type UptimeController struct {
+	helperService *service.HelperService
+}
+
+func NewUptimeController(helperService *service.HelperService) *UptimeController {
+	return &UptimeController{
+		helperService: helperService,
+	}
+}
+
+func (controller *UptimeController) GetReport(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	if request.Method != http.MethodGet {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := request.URL.Query()
+
+	format := query.Get("format")
+	if format != "" && format != uptime.FORMAT_JSON && format != uptime.FORMAT_CSV {
+		writeError(writer, http.StatusBadRequest, "Invalid format: "+format)
+		return
+	}
+
+	from, to, err := uptime.ParsePeriod(query.Get("month"), query.Get("from"), query.Get("to"), time.Now())
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reports, err := controller.helperService.GetUptimeReports(query.Get("group"), from, to)
+	if err != nil {
+		log.Error(&ctx).Msg("Error on reading uptime history: " + err.Error())
+		writeError(writer, http.StatusInternalServerError, "Error on reading uptime history")
+		return
+	}
+
+	if format == uptime.FORMAT_CSV {
+		writer.Header().Set("Content-Type", "text/csv")
+		writer.WriteHeader(http.StatusOK)
+
+		err = uptime.WriteCSV(writer, reports)
+		if err != nil {
+			log.Error(&ctx).Msg("Error on writing uptime report: " + err.Error())
+		}
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"from":    from,
+		"to":      to,
+		"reports": reports,
+	})
+}
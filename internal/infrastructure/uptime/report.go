@@ -0,0 +1,348 @@
+package uptime
+
+import (
+	"encoding/csv"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	FORMAT_JSON = "json"
+	FORMAT_CSV  = "csv"
+
+	MONTH_LAYOUT = "2006-01"
+
+	CSV_KIND_SUMMARY = "summary"
+	CSV_KIND_OUTAGE  = "outage"
+	CSV_KIND_PROBE   = "probe"
+
+	// MAX_GAP_FACTOR marks the time between two group samples as unobserved when it exceeds this many
+	// typical check intervals, the helper was not running then and the ISP was neither up nor down
+	MAX_GAP_FACTOR = 2
+)
+
+// Outage ends at the up sample that closed it, an outage without one is ongoing when it was open at the
+// last sample and interrupted when the samples stopped in the middle of the window, both are left out of MTTR
+type Outage struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Ongoing         bool      `json:"ongoing"`
+	Interrupted     bool      `json:"interrupted"`
+}
+
+type ProbeStats struct {
+	Probe          string  `json:"probe"`
+	Samples        int     `json:"samples"`
+	Failures       int     `json:"failures"`
+	Availability   float64 `json:"availability"`
+	AverageLatency int64   `json:"averageLatencyMs"`
+}
+
+// Report covers the requested From-To window, but downtime and availability can only be measured
+// while the helper was sampling. ObservedFrom-ObservedTo is the span of the samples, ObservedSeconds
+// leaves out the gaps inside it and Coverage is the share of the window it represents
+type Report struct {
+	Group                string       `json:"group"`
+	From                 time.Time    `json:"from"`
+	To                   time.Time    `json:"to"`
+	Samples              int          `json:"samples"`
+	ObservedFrom         *time.Time   `json:"observedFrom,omitempty"`
+	ObservedTo           *time.Time   `json:"observedTo,omitempty"`
+	ObservedSeconds      float64      `json:"observedSeconds"`
+	Coverage             float64      `json:"coverage"`
+	Outages              []Outage     `json:"outages"`
+	TotalDowntimeSeconds float64      `json:"totalDowntimeSeconds"`
+	MTTRSeconds          float64      `json:"mttrSeconds"`
+	Availability         float64      `json:"availability"`
+	Probes               []ProbeStats `json:"probes"`
+}
+
+// ParsePeriod resolves the report window from a month (2006-01) or from/to values,
+// where from also accepts a duration relative to now. Defaults to the current month.
+func ParsePeriod(month string, from string, to string, now time.Time) (time.Time, time.Time, error) {
+	if utils.IsNotEmptyStr(month) {
+		start, err := time.ParseInLocation(MONTH_LAYOUT, month, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid month, expected YYYY-MM: " + month)
+		}
+
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	end := now
+
+	if utils.IsNotEmptyStr(from) {
+		duration, err := time.ParseDuration(from)
+		if err == nil {
+			start = now.Add(-duration)
+		} else {
+			start, err = time.Parse(time.RFC3339, from)
+			if err != nil {
+				return time.Time{}, time.Time{}, errors.New("Invalid from, expected a duration or RFC 3339 time: " + from)
+			}
+		}
+	}
+
+	if utils.IsNotEmptyStr(to) {
+		var err error
+
+		end, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("Invalid to, expected RFC 3339 time: " + to)
+		}
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, errors.New("Invalid period, from must be before to")
+	}
+
+	return start, end, nil
+}
+
+// BuildReports groups samples by fallback group. Outages are measured from the first down
+// group state until the next up one, an outage still open at the last sample is reported as ongoing
+// and one open before a gap in the samples as interrupted.
+func BuildReports(samples []Sample, from time.Time, to time.Time) []Report {
+	samplesByGroup := make(map[string][]Sample)
+	for _, sample := range samples {
+		samplesByGroup[sample.Group] = append(samplesByGroup[sample.Group], sample)
+	}
+
+	groups := make([]string, 0, len(samplesByGroup))
+	for group := range samplesByGroup {
+		groups = append(groups, group)
+	}
+
+	sort.Strings(groups)
+
+	reports := make([]Report, 0, len(groups))
+	for _, group := range groups {
+		reports = append(reports, buildReport(group, samplesByGroup[group], from, to))
+	}
+
+	return reports
+}
+
+func buildReport(group string, samples []Sample, from time.Time, to time.Time) Report {
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Time.Before(samples[j].Time)
+	})
+
+	report := Report{
+		Group:   group,
+		From:    from,
+		To:      to,
+		Outages: []Outage{},
+		Probes:  []ProbeStats{},
+	}
+
+	var groupSamples []Sample
+	probeStats := make(map[string]*ProbeStats)
+	probeLatencies := make(map[string]time.Duration)
+
+	for _, sample := range samples {
+		if utils.IsEmptyStr(sample.Probe) {
+			groupSamples = append(groupSamples, sample)
+			continue
+		}
+
+		stats, exists := probeStats[sample.Probe]
+		if !exists {
+			stats = &ProbeStats{Probe: sample.Probe}
+			probeStats[sample.Probe] = stats
+		}
+
+		stats.Samples++
+		probeLatencies[sample.Probe] += sample.Latency
+		if !sample.Up {
+			stats.Failures++
+		}
+	}
+
+	report.Samples = len(groupSamples)
+
+	var observed time.Duration
+	var downtime time.Duration
+	var repairTime time.Duration
+	var repaired int
+	var current *Outage
+
+	closeOutage := func(end time.Time, ongoing bool, interrupted bool) {
+		duration := end.Sub(current.Start)
+		current.End = end
+		current.DurationSeconds = duration.Seconds()
+		current.Ongoing = ongoing
+		current.Interrupted = interrupted
+		downtime += duration
+
+		if !ongoing && !interrupted {
+			repairTime += duration
+			repaired++
+		}
+
+		report.Outages = append(report.Outages, *current)
+		current = nil
+	}
+
+	maxGap := MAX_GAP_FACTOR * typicalInterval(groupSamples)
+
+	for index, sample := range groupSamples {
+		if index > 0 {
+			previous := groupSamples[index-1].Time
+			interval := sample.Time.Sub(previous)
+
+			if interval > maxGap {
+				if current != nil {
+					closeOutage(previous, false, true)
+				}
+			} else {
+				observed += interval
+			}
+		}
+
+		if !sample.Up && current == nil {
+			current = &Outage{Start: sample.Time}
+		} else if sample.Up && current != nil {
+			closeOutage(sample.Time, false, false)
+		}
+	}
+
+	if len(groupSamples) > 0 {
+		first := groupSamples[0].Time
+		last := groupSamples[len(groupSamples)-1].Time
+		report.ObservedFrom = &first
+		report.ObservedTo = &last
+
+		if current != nil {
+			closeOutage(last, true, false)
+		}
+	}
+
+	report.ObservedSeconds = observed.Seconds()
+	if window := to.Sub(from); window > 0 {
+		report.Coverage = 100 * min(observed.Seconds()/window.Seconds(), 1)
+	}
+
+	report.TotalDowntimeSeconds = downtime.Seconds()
+	report.Availability = 100
+
+	// an ongoing or interrupted outage has no known repair time yet
+	if repaired > 0 {
+		report.MTTRSeconds = repairTime.Seconds() / float64(repaired)
+	}
+
+	if observed > 0 {
+		report.Availability = 100 * (1 - downtime.Seconds()/observed.Seconds())
+	}
+
+	for _, stats := range probeStats {
+		stats.Availability = 100 * float64(stats.Samples-stats.Failures) / float64(stats.Samples)
+		stats.AverageLatency = (probeLatencies[stats.Probe] / time.Duration(stats.Samples)).Milliseconds()
+		report.Probes = append(report.Probes, *stats)
+	}
+
+	sort.Slice(report.Probes, func(i, j int) bool {
+		return report.Probes[i].Probe < report.Probes[j].Probe
+	})
+
+	return report
+}
+
+// typicalInterval is the median time between consecutive samples, it follows the check schedule
+// of the group without knowing it, whether an interval or a cron expression
+func typicalInterval(samples []Sample) time.Duration {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	intervals := make([]time.Duration, 0, len(samples)-1)
+	for index := 1; index < len(samples); index++ {
+		intervals = append(intervals, samples[index].Time.Sub(samples[index-1].Time))
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i] < intervals[j]
+	})
+
+	return intervals[len(intervals)/2]
+}
+
+// WriteCSV writes one summary row per group followed by its outage and probe rows
+func WriteCSV(writer io.Writer, reports []Report) error {
+	csvWriter := csv.NewWriter(writer)
+
+	err := csvWriter.Write([]string{
+		"kind", "group", "probe", "start", "end", "observed_start", "observed_end", "coverage_percent",
+		"samples", "outages", "downtime_seconds", "mttr_seconds", "availability_percent", "average_latency_ms",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		rows := [][]string{{
+			CSV_KIND_SUMMARY, report.Group, "", formatTime(report.From), formatTime(report.To),
+			formatOptionalTime(report.ObservedFrom), formatOptionalTime(report.ObservedTo), formatPercent(report.Coverage),
+			strconv.Itoa(report.Samples), strconv.Itoa(len(report.Outages)),
+			formatSeconds(report.TotalDowntimeSeconds), formatSeconds(report.MTTRSeconds), formatPercent(report.Availability), "",
+		}}
+
+		for _, outage := range report.Outages {
+			rows = append(rows, []string{
+				CSV_KIND_OUTAGE, report.Group, "", formatTime(outage.Start), formatTime(outage.End), "", "", "", "", "",
+				formatSeconds(outage.DurationSeconds), "", "", "",
+			})
+		}
+
+		for _, stats := range report.Probes {
+			rows = append(rows, []string{
+				CSV_KIND_PROBE, report.Group, stats.Probe, formatTime(report.From), formatTime(report.To), "", "", "", strconv.Itoa(stats.Samples), "",
+				"", "", formatPercent(stats.Availability), strconv.FormatInt(stats.AverageLatency, 10),
+			})
+		}
+
+		err = csvWriter.WriteAll(rows)
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func formatTime(value time.Time) string {
+	return value.Format(time.RFC3339)
+}
+
+func formatOptionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+
+	return formatTime(*value)
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 0, 64)
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', 4, 64)
+}
+
+// Query reads the stored history and builds the reports of the period
+func Query(path string, group string, from time.Time, to time.Time) ([]Report, error) {
+	samples, err := Read(path, group, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildReports(samples, from, to), nil
+}
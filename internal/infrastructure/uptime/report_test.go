@@ -0,0 +1,226 @@
+package uptime
+
+import (
+	"encoding/csv"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+func groupSample(offset time.Duration, up bool) Sample {
+	return Sample{Time: base.Add(offset), Group: "home", Up: up}
+}
+
+func TestParsePeriod(t *testing.T) {
+	now := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		month  string
+		from   string
+		to     string
+		start  time.Time
+		end    time.Time
+		failed bool
+	}{
+		{"default month", "", "", "", time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), now, false},
+		{"month", "2024-02", "", "", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), false},
+		{"relative from", "", "24h", "", now.Add(-24 * time.Hour), now, false},
+		{"absolute range", "", "2024-05-10T00:00:00Z", "2024-05-11T00:00:00Z", time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC), time.Date(2024, time.May, 11, 0, 0, 0, 0, time.UTC), false},
+		{"invalid month", "2024-13", "", "", time.Time{}, time.Time{}, true},
+		{"invalid from", "", "yesterday", "", time.Time{}, time.Time{}, true},
+		{"reversed range", "", "2024-05-11T00:00:00Z", "2024-05-10T00:00:00Z", time.Time{}, time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, err := ParsePeriod(test.month, test.from, test.to, now)
+			if (err != nil) != test.failed {
+				t.Fatalf("ParsePeriod error = %v, expected failure %t", err, test.failed)
+			}
+
+			if !test.failed && (!start.Equal(test.start) || !end.Equal(test.end)) {
+				t.Errorf("ParsePeriod = %s - %s, expected %s - %s", start, end, test.start, test.end)
+			}
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	tests := []struct {
+		name         string
+		samples      []Sample
+		outages      int
+		downtime     float64
+		mttr         float64
+		availability float64
+		coverage     float64
+		ongoing      bool
+	}{
+		{"no samples", nil, 0, 0, 0, 100, 0, false},
+		{"always up", []Sample{groupSample(0, true), groupSample(time.Hour, true)}, 0, 0, 0, 100, 100.0 / 24, false},
+		{"one outage", []Sample{
+			groupSample(0, true), groupSample(time.Hour, false), groupSample(90*time.Minute, false), groupSample(2*time.Hour, true), groupSample(4*time.Hour, true),
+		}, 1, 3600, 3600, 75, 100.0 / 6, false},
+		{"two outages", []Sample{
+			groupSample(0, false), groupSample(time.Hour, true), groupSample(2*time.Hour, false), groupSample(5*time.Hour, true), groupSample(8*time.Hour, true),
+		}, 2, 4 * 3600, 2 * 3600, 50, 100.0 / 3, false},
+		{"ongoing outage left out of mttr", []Sample{groupSample(0, true), groupSample(time.Hour, false), groupSample(2*time.Hour, false)}, 1, 3600, 0, 50, 100.0 / 12, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := buildReport("home", test.samples, base, base.Add(24*time.Hour))
+
+			if len(report.Outages) != test.outages {
+				t.Fatalf("outages = %d, expected %d", len(report.Outages), test.outages)
+			}
+
+			if report.TotalDowntimeSeconds != test.downtime || report.MTTRSeconds != test.mttr {
+				t.Errorf("downtime %f, mttr %f, expected %f and %f", report.TotalDowntimeSeconds, report.MTTRSeconds, test.downtime, test.mttr)
+			}
+
+			if math.Abs(report.Availability-test.availability) > 1e-9 || math.Abs(report.Coverage-test.coverage) > 1e-9 {
+				t.Errorf("availability %f, coverage %f, expected %f and %f", report.Availability, report.Coverage, test.availability, test.coverage)
+			}
+
+			if test.outages > 0 && report.Outages[test.outages-1].Ongoing != test.ongoing {
+				t.Errorf("last outage ongoing = %t, expected %t", report.Outages[test.outages-1].Ongoing, test.ongoing)
+			}
+
+			if len(test.samples) > 0 && (!report.ObservedFrom.Equal(test.samples[0].Time) || !report.ObservedTo.Equal(test.samples[len(test.samples)-1].Time)) {
+				t.Errorf("observed %s - %s does not match the samples", report.ObservedFrom, report.ObservedTo)
+			}
+		})
+	}
+}
+
+// every10Minutes samples the group from start to end included
+func every10Minutes(start time.Duration, end time.Duration, up bool) []Sample {
+	var samples []Sample
+	for offset := start; offset <= end; offset += 10 * time.Minute {
+		samples = append(samples, groupSample(offset, up))
+	}
+
+	return samples
+}
+
+func TestBuildReportGaps(t *testing.T) {
+	tests := []struct {
+		name         string
+		samples      []Sample
+		observed     time.Duration
+		downtime     time.Duration
+		mttr         time.Duration
+		availability float64
+		interrupted  []bool
+	}{
+		{"gap after up sample", append(every10Minutes(0, time.Hour, true), every10Minutes(5*time.Hour, 6*time.Hour, true)...),
+			2 * time.Hour, 0, 0, 100, nil},
+		{"gap after down sample", append(append([]Sample{groupSample(0, true)}, every10Minutes(10*time.Minute, 20*time.Minute, false)...),
+			append(every10Minutes(2*time.Hour, 2*time.Hour, false), every10Minutes(130*time.Minute, 150*time.Minute, true)...)...),
+			50 * time.Minute, 20 * time.Minute, 10 * time.Minute, 60, []bool{true, false}},
+		{"slow jitter is not a gap", []Sample{groupSample(0, true), groupSample(10*time.Minute, false), groupSample(29*time.Minute, true), groupSample(40*time.Minute, true)},
+			40 * time.Minute, 19 * time.Minute, 19 * time.Minute, 100 * (1 - 19.0/40), []bool{false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := buildReport("home", test.samples, base, base.Add(24*time.Hour))
+
+			if report.ObservedSeconds != test.observed.Seconds() || report.TotalDowntimeSeconds != test.downtime.Seconds() || report.MTTRSeconds != test.mttr.Seconds() {
+				t.Errorf("observed %f, downtime %f, mttr %f, expected %f, %f and %f", report.ObservedSeconds, report.TotalDowntimeSeconds, report.MTTRSeconds,
+					test.observed.Seconds(), test.downtime.Seconds(), test.mttr.Seconds())
+			}
+
+			if coverage := 100 * test.observed.Seconds() / (24 * time.Hour).Seconds(); math.Abs(report.Coverage-coverage) > 1e-9 {
+				t.Errorf("coverage %f, expected %f", report.Coverage, coverage)
+			}
+
+			if math.Abs(report.Availability-test.availability) > 1e-9 {
+				t.Errorf("availability %f, expected %f", report.Availability, test.availability)
+			}
+
+			if len(report.Outages) != len(test.interrupted) {
+				t.Fatalf("outages %+v, expected %d", report.Outages, len(test.interrupted))
+			}
+
+			for index, interrupted := range test.interrupted {
+				if report.Outages[index].Interrupted != interrupted {
+					t.Errorf("outage %d interrupted = %t, expected %t", index, report.Outages[index].Interrupted, interrupted)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildReportProbes(t *testing.T) {
+	samples := []Sample{
+		{Time: base, Group: "home", Probe: "http", Up: true, Latency: 100 * time.Millisecond},
+		{Time: base.Add(time.Minute), Group: "home", Probe: "http", Up: false, Latency: 300 * time.Millisecond},
+		{Time: base, Group: "home", Probe: "tcp", Up: true, Latency: 10 * time.Millisecond},
+	}
+
+	report := buildReport("home", samples, base, base.Add(time.Hour))
+	if len(report.Probes) != 2 || report.Probes[0].Probe != "http" {
+		t.Fatalf("unexpected probes %v", report.Probes)
+	}
+
+	http := report.Probes[0]
+	if http.Samples != 2 || http.Failures != 1 || http.Availability != 50 || http.AverageLatency != 200 {
+		t.Errorf("unexpected http stats %+v", http)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	samples := []Sample{
+		groupSample(0, true), groupSample(time.Hour, false), groupSample(2*time.Hour, true),
+		{Time: base, Group: "home", Probe: "http", Up: true},
+	}
+
+	var builder strings.Builder
+	err := WriteCSV(&builder, BuildReports(samples, base, base.Add(24*time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(builder.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []string{"kind", CSV_KIND_SUMMARY, CSV_KIND_OUTAGE, CSV_KIND_PROBE}
+	if len(rows) != len(kinds) {
+		t.Fatalf("expected %d rows, got %d", len(kinds), len(rows))
+	}
+
+	for index, row := range rows {
+		if row[0] != kinds[index] {
+			t.Errorf("row %d kind = %s, expected %s", index, row[0], kinds[index])
+		}
+	}
+
+	if rows[1][5] != "2024-05-01T00:00:00Z" || rows[1][6] != "2024-05-01T02:00:00Z" {
+		t.Errorf("summary observed span = %s - %s", rows[1][5], rows[1][6])
+	}
+}
+
+func TestSampleRoundTrip(t *testing.T) {
+	sample := Sample{Time: time.UnixMilli(1714521600123), Group: "home,a", Probe: "http", Up: true, Latency: 42 * time.Millisecond, Class: "success"}
+
+	decoded, err := decode(sample.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.Time.Equal(sample.Time) || decoded.Group != "home_a" || decoded.Probe != "http" || !decoded.Up || decoded.Latency != sample.Latency || decoded.Class != "success" {
+		t.Errorf("decoded %+v from %+v", decoded, sample)
+	}
+
+	if _, err := decode("1,home,up"); err == nil {
+		t.Error("short line decoded")
+	}
+}
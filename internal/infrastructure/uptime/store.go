@@ -0,0 +1,176 @@
+package uptime
+
+import (
+	"bufio"
+	"errors"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils"
+	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/rotate"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_PATH = "logs/uptime.csv"
+
+	FIELD_SEPARATOR = ","
+	SAMPLE_FIELDS   = 6
+)
+
+// Sample is one probe result, or the group state when Probe is empty.
+// It is stored as a compact line: unix millis,group,probe,up,latency millis,class
+type Sample struct {
+	Time    time.Time
+	Group   string
+	Probe   string
+	Up      bool
+	Latency time.Duration
+	Class   string
+}
+
+type Store struct {
+	writer *rotate.Writer
+	mutex  sync.Mutex
+}
+
+func NewStore(uptimeConfig config.Uptime) (*Store, error) {
+	if !uptimeConfig.Enabled {
+		return &Store{}, nil
+	}
+
+	fileConfig := uptimeConfig.File
+	writer, err := rotate.NewWriter(Path(uptimeConfig), fileConfig.MaxSize, fileConfig.Interval, fileConfig.MaxAge, fileConfig.MaxBackups)
+	if err != nil {
+		return nil, errors.New("Error on opening uptime history: " + err.Error())
+	}
+
+	return &Store{
+		writer: writer,
+	}, nil
+}
+
+func Path(uptimeConfig config.Uptime) string {
+	if utils.IsEmptyStr(uptimeConfig.File.Path) {
+		return DEFAULT_PATH
+	}
+
+	return uptimeConfig.File.Path
+}
+
+func (store *Store) Record(samples ...Sample) error {
+	if store.writer == nil {
+		return nil
+	}
+
+	var builder strings.Builder
+	for _, sample := range samples {
+		builder.WriteString(sample.encode())
+		builder.WriteString("\n")
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	_, err := store.writer.Write([]byte(builder.String()))
+	return err
+}
+
+// Read loads the samples of a group between from and to, oldest first
+func Read(path string, group string, from time.Time, to time.Time) ([]Sample, error) {
+	var samples []Sample
+
+	for _, file := range rotate.Files(path) {
+		fileSamples, err := readFile(file, group, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		samples = append(samples, fileSamples...)
+	}
+
+	return samples, nil
+}
+
+func readFile(path string, group string, from time.Time, to time.Time) ([]Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer file.Close()
+
+	var samples []Sample
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		sample, err := decode(scanner.Text())
+		if err != nil {
+			continue
+		}
+
+		if utils.IsNotEmptyStr(group) && sample.Group != group {
+			continue
+		}
+
+		if sample.Time.Before(from) || !sample.Time.Before(to) {
+			continue
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples, scanner.Err()
+}
+
+func (sample Sample) encode() string {
+	up := "0"
+	if sample.Up {
+		up = "1"
+	}
+
+	return strings.Join([]string{
+		strconv.FormatInt(sample.Time.UnixMilli(), 10),
+		sanitize(sample.Group),
+		sanitize(sample.Probe),
+		up,
+		strconv.FormatInt(sample.Latency.Milliseconds(), 10),
+		sanitize(sample.Class),
+	}, FIELD_SEPARATOR)
+}
+
+func decode(line string) (Sample, error) {
+	fields := strings.Split(line, FIELD_SEPARATOR)
+	if len(fields) != SAMPLE_FIELDS {
+		return Sample{}, fmt.Errorf("Invalid uptime sample: %s", line)
+	}
+
+	millis, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return Sample{}, err
+	}
+
+	latency, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return Sample{}, err
+	}
+
+	return Sample{
+		Time:    time.UnixMilli(millis),
+		Group:   fields[1],
+		Probe:   fields[2],
+		Up:      fields[3] == "1",
+		Latency: time.Duration(latency) * time.Millisecond,
+		Class:   fields[5],
+	}, nil
+}
+
+func sanitize(value string) string {
+	return strings.NewReplacer(FIELD_SEPARATOR, "_", "\n", "_").Replace(value)
+}
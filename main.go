@@ -4,32 +4,104 @@ import (
 	"context"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/common/utils/log"
 	"fernandoglatz/aws-infrastructure-helper/internal/core/service"
+	"os"
 
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/cli"
 	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/config"
+	"fernandoglatz/aws-infrastructure-helper/internal/infrastructure/server"
 
 	"github.com/joho/godotenv"
 )
 
+const AGENT_MODE = "agent"
+
 func main() {
 	ctx := context.Background()
 	godotenv.Load()
 
+	command := ""
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	if command == cli.AUDIT_COMMAND || command == cli.UPTIME_COMMAND || command == cli.SCHEDULED_COMMAND {
+		log.UseStderr()
+	}
+
 	err := config.LoadConfig(&ctx)
 	if err != nil {
 		log.Fatal(&ctx).Msg(err.Error())
 	}
 
-	helperService := service.NewHelperService()
+	switch command {
+	case AGENT_MODE:
+		startAgent(&ctx)
+
+	case cli.AUDIT_COMMAND:
+		runCommand(&ctx, cli.RunAudit)
+
+	case cli.UPTIME_COMMAND:
+		runCommand(&ctx, cli.RunUptime)
+
+	case cli.SCHEDULED_COMMAND:
+		runCommand(&ctx, cli.RunScheduled)
+
+	default:
+		startHelper(&ctx)
+	}
 
-	err = helperService.ScheduleDNSUpdater(&ctx)
+	select {}
+}
+
+func runCommand(ctx *context.Context, command func(args []string) error) {
+	err := command(os.Args[2:])
 	if err != nil {
-		log.Fatal(&ctx).Msg(err.Error())
+		log.Fatal(ctx).Msg(err.Error())
 	}
 
-	err = helperService.ScheduleISPFallback(&ctx)
+	os.Exit(0)
+}
+
+func startHelper(ctx *context.Context) {
+	helperService, err := service.NewHelperService()
 	if err != nil {
-		log.Fatal(&ctx).Msg(err.Error())
+		log.Fatal(ctx).Msg(err.Error())
 	}
 
-	select {}
+	helperService.StartMetrics(ctx)
+	helperService.StartElection(ctx)
+
+	err = helperService.ScheduleDNSUpdater(ctx)
+	if err != nil {
+		log.Fatal(ctx).Msg(err.Error())
+	}
+
+	err = helperService.ScheduleISPFallback(ctx)
+	if err != nil {
+		log.Fatal(ctx).Msg(err.Error())
+	}
+
+	helperServer, err := server.NewServer(helperService)
+	if err != nil {
+		log.Fatal(ctx).Msg(err.Error())
+	}
+
+	err = helperServer.Start(ctx)
+	if err != nil {
+		log.Fatal(ctx).Msg(err.Error())
+	}
+}
+
+func startAgent(ctx *context.Context) {
+	log.Info(ctx).Msg("Starting in agent mode")
+
+	agentServer, err := server.NewAgentServer()
+	if err != nil {
+		log.Fatal(ctx).Msg(err.Error())
+	}
+
+	err = agentServer.Start(ctx)
+	if err != nil {
+		log.Fatal(ctx).Msg(err.Error())
+	}
 }